@@ -0,0 +1,369 @@
+package ams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/abi"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// abiMaxAppArgs is the number of application args; ARC-4 packs the method args past the 14th into a tuple in the last one
+const abiMaxAppArgs = 16
+
+// abiSpec is the common part of ARC-4 contract and ARC-56 app spec json files
+type abiSpec struct {
+	Name     string       `json:"name" yaml:"name"`
+	Methods  []abi.Method `json:"methods"`
+	Networks map[string]struct {
+		AppID uint64 `json:"appID"`
+	} `json:"networks"`
+}
+
+// ABIRegistry maps application ids to their ARC-4 contracts
+type ABIRegistry struct {
+	specs map[uint64]*abiSpec
+}
+
+type ABIRegistryOption func(r *ABIRegistry) error
+
+// WithABIRegistryDir loads every json spec in the directory. The app ids are taken from the spec networks
+// and from a numeric file name prefix, e.g. 31566704.json or 31566704-pool.json.
+func WithABIRegistryDir(dir string) ABIRegistryOption {
+	return func(r *ABIRegistry) error {
+		if len(dir) == 0 {
+			return nil
+		}
+
+		paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return errors.Wrap(err, "failed to list abi spec files")
+		}
+
+		for _, p := range paths {
+			err = r.loadFile(p)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithABIRegistrySpec registers a spec json for the app id in addition to the ids found in the spec itself
+func WithABIRegistrySpec(appID uint64, spec []byte) ABIRegistryOption {
+	return func(r *ABIRegistry) error {
+		return r.add(spec, "spec", []uint64{appID})
+	}
+}
+
+func MakeABIRegistry(opts ...ABIRegistryOption) (*ABIRegistry, error) {
+	r := &ABIRegistry{
+		specs: map[uint64]*abiSpec{},
+	}
+
+	for _, opt := range opts {
+		err := opt(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func (r *ABIRegistry) loadFile(p string) error {
+	bs, err := os.ReadFile(p)
+	if err != nil {
+		return errors.Wrap(err, "failed to read abi spec file")
+	}
+
+	var ids []uint64
+
+	name := filepath.Base(p)
+	prefix := name[:len(name)-len(filepath.Ext(name))]
+	if i := strings.IndexAny(prefix, "-_."); i >= 0 {
+		prefix = prefix[:i]
+	}
+
+	if id, err := strconv.ParseUint(prefix, 10, 64); err == nil {
+		ids = append(ids, id)
+	}
+
+	return r.add(bs, p, ids)
+}
+
+func (r *ABIRegistry) add(bs []byte, source string, ids []uint64) error {
+	var spec abiSpec
+
+	err := json.Unmarshal(bs, &spec)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode abi spec: %s", source)
+	}
+
+	for _, n := range spec.Networks {
+		if n.AppID != 0 {
+			ids = append(ids, n.AppID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return errors.Errorf("no app id for abi spec: %s", source)
+	}
+
+	for _, id := range ids {
+		r.specs[id] = &spec
+	}
+
+	return nil
+}
+
+// ABIArg is a decoded ARC-4 method argument
+type ABIArg struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	Type string `json:"type" yaml:"type"`
+	// Value is the json encoded argument, absent for transaction args
+	Value json.RawMessage `json:"value,omitempty" yaml:"value,omitempty"`
+	// Reference describes what a reference arg points to, e.g. the account address
+	Reference string `json:"reference,omitempty" yaml:"reference,omitempty"`
+}
+
+func (a ABIArg) String() string {
+	var parts []string
+
+	if len(a.Value) > 0 {
+		parts = append(parts, string(a.Value))
+	}
+
+	if len(a.Reference) > 0 {
+		parts = append(parts, a.Reference)
+	}
+
+	return strings.Join(parts, " - ")
+}
+
+// MarshalYAML renders the json encoded value as a yaml value instead of bytes
+func (a ABIArg) MarshalYAML() (interface{}, error) {
+	var value interface{}
+
+	if len(a.Value) > 0 {
+		err := json.Unmarshal(a.Value, &value)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode arg json")
+		}
+	}
+
+	return struct {
+		Name      string      `yaml:"name,omitempty"`
+		Type      string      `yaml:"type"`
+		Value     interface{} `yaml:"value,omitempty"`
+		Reference string      `yaml:"reference,omitempty"`
+	}{a.Name, a.Type, value, a.Reference}, nil
+}
+
+// ABICall is a decoded ARC-4 application call
+type ABICall struct {
+	Contract  string   `json:"contract" yaml:"contract"`
+	Method    string   `json:"method" yaml:"method"`
+	Signature string   `json:"signature" yaml:"signature"`
+	Args      []ABIArg `json:"args" yaml:"args"`
+}
+
+func abiReference(txn types.Transaction, typ string, idx int) (json.RawMessage, string, error) {
+	switch typ {
+	case abi.AccountReferenceType:
+		switch {
+		case idx == 0:
+			return json.RawMessage(strconv.Quote(txn.Sender.String())), "sender", nil
+		case idx <= len(txn.Accounts):
+			return json.RawMessage(strconv.Quote(txn.Accounts[idx-1].String())), fmt.Sprintf("foreign account #%d", idx), nil
+		}
+	case abi.AssetReferenceType:
+		if idx < len(txn.ForeignAssets) {
+			return json.RawMessage(strconv.FormatUint(uint64(txn.ForeignAssets[idx]), 10)), fmt.Sprintf("foreign asset #%d", idx), nil
+		}
+	case abi.ApplicationReferenceType:
+		switch {
+		case idx == 0:
+			return json.RawMessage(strconv.FormatUint(uint64(txn.ApplicationID), 10)), "current app", nil
+		case idx <= len(txn.ForeignApps):
+			return json.RawMessage(strconv.FormatUint(uint64(txn.ForeignApps[idx-1]), 10)), fmt.Sprintf("foreign app #%d", idx), nil
+		}
+	}
+
+	return nil, "", errors.Errorf("invalid %s reference index: %d", typ, idx)
+}
+
+// DecodeAppCall decodes the application call args with the registered contract; nil is returned for calls
+// of unknown apps or methods
+func (r *ABIRegistry) DecodeAppCall(txn types.Transaction) (*ABICall, error) {
+	if r == nil || txn.Type != types.ApplicationCallTx || len(txn.ApplicationArgs) == 0 {
+		return nil, nil
+	}
+
+	spec, ok := r.specs[uint64(txn.ApplicationID)]
+	if !ok {
+		return nil, nil
+	}
+
+	var method *abi.Method
+	for i := range spec.Methods {
+		if bytes.Equal(spec.Methods[i].GetSelector(), txn.ApplicationArgs[0]) {
+			method = &spec.Methods[i]
+			break
+		}
+	}
+
+	if method == nil {
+		return nil, nil
+	}
+
+	call := &ABICall{
+		Contract:  spec.Name,
+		Method:    method.Name,
+		Signature: method.GetSignature(),
+	}
+
+	// raw holds the encoded value of every non-transaction arg
+	var raw [][]byte
+	var encoded int
+
+	for _, a := range method.Args {
+		if !a.IsTransactionArg() {
+			encoded++
+		}
+	}
+
+	args := txn.ApplicationArgs[1:]
+
+	if encoded > abiMaxAppArgs-1 {
+		if len(args) != abiMaxAppArgs-1 {
+			return nil, errors.Errorf("invalid number of app args for method %s: %d", call.Signature, len(txn.ApplicationArgs))
+		}
+
+		raw = append(raw, args[:abiMaxAppArgs-2]...)
+
+		// the remaining args are encoded as a tuple in the last app arg
+		var tupleTypes []abi.Type
+		count := 0
+		for _, a := range method.Args {
+			if a.IsTransactionArg() {
+				continue
+			}
+
+			count++
+			if count < abiMaxAppArgs-1 {
+				continue
+			}
+
+			t, err := abiArgType(a)
+			if err != nil {
+				return nil, err
+			}
+
+			tupleTypes = append(tupleTypes, t)
+		}
+
+		tt, err := abi.MakeTupleType(tupleTypes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make args tuple type")
+		}
+
+		values, err := tt.Decode(args[abiMaxAppArgs-2])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode args tuple")
+		}
+
+		for i, v := range values.([]interface{}) {
+			bs, err := tupleTypes[i].Encode(v)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to encode tuple arg")
+			}
+
+			raw = append(raw, bs)
+		}
+	} else {
+		if len(args) != encoded {
+			return nil, errors.Errorf("invalid number of app args for method %s: %d", call.Signature, len(txn.ApplicationArgs))
+		}
+
+		raw = args
+	}
+
+	next := 0
+
+	for _, a := range method.Args {
+		arg := ABIArg{
+			Name: a.Name,
+			Type: a.Type,
+		}
+
+		if a.IsTransactionArg() {
+			arg.Reference = "preceding group transaction"
+			call.Args = append(call.Args, arg)
+			continue
+		}
+
+		bs := raw[next]
+		next++
+
+		if a.IsReferenceArg() {
+			if len(bs) != 1 {
+				return nil, errors.Errorf("invalid reference arg encoding: %s", a.Type)
+			}
+
+			value, ref, err := abiReference(txn, a.Type, int(bs[0]))
+			if err != nil {
+				return nil, err
+			}
+
+			arg.Value = value
+			arg.Reference = ref
+			call.Args = append(call.Args, arg)
+			continue
+		}
+
+		t, err := abiArgType(a)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := t.Decode(bs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode arg: %s", a.Type)
+		}
+
+		js, err := t.MarshalToJSON(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to encode arg json: %s", a.Type)
+		}
+
+		arg.Value = js
+		call.Args = append(call.Args, arg)
+	}
+
+	return call, nil
+}
+
+func abiArgType(a abi.Arg) (abi.Type, error) {
+	typ := a.Type
+
+	// reference args are encoded as the uint8 index of the foreign array
+	if a.IsReferenceArg() {
+		typ = "uint8"
+	}
+
+	t, err := abi.TypeOf(typ)
+	if err != nil {
+		return t, errors.Wrapf(err, "failed to parse abi type: %s", a.Type)
+	}
+
+	return t, nil
+}
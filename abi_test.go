@@ -0,0 +1,143 @@
+package ams
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/abi"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const abiTestSpec = `{
+	"name": "Vault",
+	"methods": [
+		{
+			"name": "withdraw",
+			"args": [
+				{"type": "pay", "name": "fee"},
+				{"type": "account", "name": "to"},
+				{"type": "asset", "name": "token"},
+				{"type": "uint64", "name": "amount"},
+				{"type": "string", "name": "memo"}
+			],
+			"returns": {"type": "void"}
+		}
+	],
+	"networks": {
+		"wGHE2Pwdvd7S12BL5FaOP20EGYesN73ktiC1qzkkit8=": {"appID": 1234}
+	}
+}`
+
+func abiTestEncode(t *testing.T, typ string, v interface{}) []byte {
+	at, err := abi.TypeOf(typ)
+	assert.NoError(t, err)
+
+	bs, err := at.Encode(v)
+	assert.NoError(t, err)
+
+	return bs
+}
+
+func TestABIRegistryDecodeAppCall(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "vault.json"), []byte(abiTestSpec), 0644))
+
+	r, err := MakeABIRegistry(WithABIRegistryDir(dir))
+	assert.NoError(t, err)
+
+	m, err := abi.MethodFromSignature("withdraw(pay,account,asset,uint64,string)void")
+	assert.NoError(t, err)
+
+	acc := crypto.GenerateAccount()
+	to := crypto.GenerateAccount()
+
+	txn := types.Transaction{
+		Type:   types.ApplicationCallTx,
+		Header: types.Header{Sender: acc.Address},
+		ApplicationFields: types.ApplicationFields{
+			ApplicationCallTxnFields: types.ApplicationCallTxnFields{
+				ApplicationID: 1234,
+				ApplicationArgs: [][]byte{
+					m.GetSelector(),
+					{1},
+					{0},
+					abiTestEncode(t, "uint64", uint64(1500)),
+					abiTestEncode(t, "string", "payout"),
+				},
+				Accounts:      []types.Address{to.Address},
+				ForeignAssets: []types.AssetIndex{31566704},
+			},
+		},
+	}
+
+	call, err := r.DecodeAppCall(txn)
+	assert.NoError(t, err)
+	assert.NotNil(t, call)
+
+	assert.Equal(t, "Vault", call.Contract)
+	assert.Equal(t, "withdraw", call.Method)
+	assert.Len(t, call.Args, 5)
+	assert.Equal(t, "preceding group transaction", call.Args[0].Reference)
+	assert.Equal(t, `"`+to.Address.String()+`"`, string(call.Args[1].Value))
+	assert.Equal(t, "31566704", string(call.Args[2].Value))
+	assert.Equal(t, "1500", string(call.Args[3].Value))
+	assert.Equal(t, `"payout"`, string(call.Args[4].Value))
+
+	s := FormatTxn(txn, WithFormatABIRegistry(r))
+	assert.Contains(t, s, "ABI method: withdraw (withdraw(pay,account,asset,uint64,string)void)")
+	assert.Contains(t, s, "ABI arg amount (uint64): 1500")
+
+	// unknown apps are not decoded
+	txn.ApplicationID = 1
+	call, err = r.DecodeAppCall(txn)
+	assert.NoError(t, err)
+	assert.Nil(t, call)
+}
+
+func TestABIRegistryDecodeTupledArgs(t *testing.T) {
+	to := crypto.GenerateAccount()
+
+	var argTypes []string
+	for i := 0; i < 15; i++ {
+		argTypes = append(argTypes, "uint64")
+	}
+	argTypes = append(argTypes, "account")
+
+	sig := fmt.Sprintf("many(%s)void", strings.Join(argTypes, ","))
+
+	m, err := abi.MethodFromSignature(sig)
+	assert.NoError(t, err)
+
+	spec := `{"name":"Many","methods":[{"name":"many","args":[` + strings.Repeat(`{"type":"uint64"},`, 15) + `{"type":"account"}],"returns":{"type":"void"}}]}`
+
+	r, err := MakeABIRegistry(WithABIRegistrySpec(55, []byte(spec)))
+	assert.NoError(t, err)
+
+	args := [][]byte{m.GetSelector()}
+	for i := 0; i < 14; i++ {
+		args = append(args, abiTestEncode(t, "uint64", uint64(i)))
+	}
+	args = append(args, abiTestEncode(t, "(uint64,uint8)", []interface{}{uint64(14), uint8(1)}))
+
+	call, err := r.DecodeAppCall(types.Transaction{
+		Type: types.ApplicationCallTx,
+		ApplicationFields: types.ApplicationFields{
+			ApplicationCallTxnFields: types.ApplicationCallTxnFields{
+				ApplicationID:   55,
+				ApplicationArgs: args,
+				Accounts:        []types.Address{to.Address},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, call.Args, 16)
+	assert.Equal(t, "14", string(call.Args[14].Value))
+	assert.Equal(t, strconv.Quote(to.Address.String()), string(call.Args[15].Value))
+	assert.Equal(t, "foreign account #1", call.Args[15].Reference)
+}
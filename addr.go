@@ -1,9 +1,12 @@
 package ams
 
 import (
+	"strconv"
 	"strings"
 
+	"github.com/algorand/go-algorand-sdk/crypto"
 	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
 )
 
 func ParseAddrs(addrs string, sep string) ([]types.Address, error) {
@@ -23,3 +26,33 @@ func ParseAddrs(addrs string, sep string) ([]types.Address, error) {
 
 	return accs, nil
 }
+
+// ParseMultisig parses a multisig account definition in the "threshold:addr1,addr2,.." format
+func ParseMultisig(value string) (*crypto.MultisigAccount, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return nil, errors.New("malformed multisig definition - expected threshold:addr1,addr2,..")
+	}
+
+	threshold, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse multisig threshold")
+	}
+
+	addrs, err := ParseAddrs(parts[1], ",")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse multisig addresses")
+	}
+
+	err = ValidateMultisig(int(threshold), addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	ma, err := crypto.MultisigAccountWithParams(1, uint8(threshold), addrs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build multisig account")
+	}
+
+	return &ma, nil
+}
@@ -26,3 +26,21 @@ func TestParseAddrs(t *testing.T) {
 
 	assert.Len(t, addrs, 2)
 }
+
+func TestParseMultisig(t *testing.T) {
+	ma, err := ParseMultisig("2:QFCRYKFTUI3RYCO4SSKJTU6VOYCKIW2KPYMAB37VYG4WRCEGEMM2FDJ4YQ,4D2VPFW5IGRJZYQURHIR6DWKYUWUI3MYTJKAKMTPKQU5R3PROZASZBFOHQ")
+	assert.NoError(t, err)
+
+	assert.Equal(t, uint8(2), ma.Threshold)
+	assert.Len(t, ma.Pks, 2)
+}
+
+func TestParseMultisigThresholdTooHigh(t *testing.T) {
+	_, err := ParseMultisig("3:QFCRYKFTUI3RYCO4SSKJTU6VOYCKIW2KPYMAB37VYG4WRCEGEMM2FDJ4YQ,4D2VPFW5IGRJZYQURHIR6DWKYUWUI3MYTJKAKMTPKQU5R3PROZASZBFOHQ")
+	assert.Error(t, err)
+}
+
+func TestParseMultisigMalformed(t *testing.T) {
+	_, err := ParseMultisig("QFCRYKFTUI3RYCO4SSKJTU6VOYCKIW2KPYMAB37VYG4WRCEGEMM2FDJ4YQ")
+	assert.Error(t, err)
+}
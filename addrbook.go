@@ -0,0 +1,218 @@
+package ams
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// addressPoisonMaxDiff is the number of differing characters below which an address resembles a labeled one
+	addressPoisonMaxDiff = 8
+	// addressPoisonAffix is the length of the prefix and suffix that poisoning addresses are usually generated to match
+	addressPoisonAffix = 4
+)
+
+// AddressBookEntry is a labeled address of the address book file
+type AddressBookEntry struct {
+	Address string `json:"address" yaml:"address"`
+	Label   string `json:"label" yaml:"label"`
+	// Bad marks addresses that must never receive funds, e.g. known scams
+	Bad bool `json:"bad,omitempty" yaml:"bad,omitempty"`
+}
+
+// AddressLabel describes an address using the address book
+type AddressLabel struct {
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+	Bad   bool   `json:"bad,omitempty" yaml:"bad,omitempty"`
+	// Resembles is set for unlabeled addresses that are nearly the same as a labeled one
+	Resembles *AddressBookEntry `json:"resembles,omitempty" yaml:"resembles,omitempty"`
+}
+
+func (l AddressLabel) String() string {
+	switch {
+	case l.Resembles != nil:
+		return fmt.Sprintf("[!] POSSIBLE ADDRESS POISONING - resembles %s (%s)", l.Resembles.Label, l.Resembles.Address)
+	case l.Bad:
+		return fmt.Sprintf("[!!!] KNOWN BAD ADDRESS: %s", l.Label)
+	default:
+		return fmt.Sprintf("(%s)", l.Label)
+	}
+}
+
+// AddressBook maps addresses to labels loaded from a json file
+type AddressBook struct {
+	entries []AddressBookEntry
+	labels  map[string]int
+}
+
+type AddressBookOption func(b *AddressBook) error
+
+// WithAddressBookFile loads the entries of a json file, e.g. [{"address": "..", "label": "Treasury"}]
+func WithAddressBookFile(path string) AddressBookOption {
+	return func(b *AddressBook) error {
+		if len(path) == 0 {
+			return nil
+		}
+
+		bs, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read address book file")
+		}
+
+		var entries []AddressBookEntry
+		err = json.Unmarshal(bs, &entries)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode address book file")
+		}
+
+		for _, e := range entries {
+			err = b.add(e)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func WithAddressBookEntry(e AddressBookEntry) AddressBookOption {
+	return func(b *AddressBook) error {
+		return b.add(e)
+	}
+}
+
+// WithAddressBookDescriptor adds the labels of the descriptor for the addresses that are not labeled yet
+func WithAddressBookDescriptor(d *MultisigDescriptor) AddressBookOption {
+	return func(b *AddressBook) error {
+		if d == nil {
+			return nil
+		}
+
+		entries := []AddressBookEntry{{Address: d.Address, Label: d.Label}}
+		for _, m := range d.Members {
+			entries = append(entries, AddressBookEntry{Address: m.Address, Label: m.Label})
+		}
+
+		for _, e := range entries {
+			if _, ok := b.labels[e.Address]; ok || len(e.Label) == 0 {
+				continue
+			}
+
+			err := b.add(e)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func MakeAddressBook(opts ...AddressBookOption) (*AddressBook, error) {
+	b := &AddressBook{
+		labels: map[string]int{},
+	}
+
+	for _, opt := range opts {
+		err := opt(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func (b *AddressBook) add(e AddressBookEntry) error {
+	_, err := types.DecodeAddress(e.Address)
+	if err != nil {
+		return errors.Wrapf(err, "invalid address book address: %s", e.Address)
+	}
+
+	if len(e.Label) == 0 {
+		return errors.Errorf("missing address book label: %s", e.Address)
+	}
+
+	if i, ok := b.labels[e.Address]; ok {
+		b.entries[i] = e
+		return nil
+	}
+
+	b.labels[e.Address] = len(b.entries)
+	b.entries = append(b.entries, e)
+
+	return nil
+}
+
+// resembles reports whether two different addresses look alike at a glance
+func resembles(a string, b string) bool {
+	if len(a) != len(b) || a == b {
+		return false
+	}
+
+	n := addressPoisonAffix
+	if a[:n] == b[:n] && a[len(a)-n:] == b[len(b)-n:] {
+		return true
+	}
+
+	diff := 0
+	for i := range a {
+		if a[i] != b[i] {
+			diff++
+		}
+	}
+
+	return diff <= addressPoisonMaxDiff
+}
+
+// Lookup returns the label of the address or the labeled address it resembles
+func (b *AddressBook) Lookup(addr string) (AddressLabel, bool) {
+	if b == nil || len(addr) == 0 {
+		return AddressLabel{}, false
+	}
+
+	if i, ok := b.labels[addr]; ok {
+		e := b.entries[i]
+		return AddressLabel{Label: e.Label, Bad: e.Bad}, true
+	}
+
+	for i := range b.entries {
+		e := b.entries[i]
+		if !e.Bad && resembles(addr, e.Address) {
+			return AddressLabel{Resembles: &e}, true
+		}
+	}
+
+	return AddressLabel{}, false
+}
+
+// Format returns the address followed by its label if there is any
+func (b *AddressBook) Format(addr string) string {
+	l, ok := b.Lookup(addr)
+	if !ok {
+		return addr
+	}
+
+	return fmt.Sprintf("%s %s", addr, l)
+}
+
+// Addresses returns the labeled addresses
+func (b *AddressBook) Addresses() []string {
+	if b == nil {
+		return nil
+	}
+
+	var res []string
+	for _, e := range b.entries {
+		if !e.Bad {
+			res = append(res, e.Address)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,89 @@
+package ams
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// lookalike changes a single character in the middle of the address
+func lookalike(addr string) string {
+	bs := []byte(addr)
+	if bs[20] == 'A' {
+		bs[20] = 'B'
+	} else {
+		bs[20] = 'A'
+	}
+
+	return string(bs)
+}
+
+func TestAddressBook(t *testing.T) {
+	treasury := crypto.GenerateAccount()
+	scam := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	p := filepath.Join(t.TempDir(), "book.json")
+	err := os.WriteFile(p, []byte(`[
+		{"address": "`+treasury.Address.String()+`", "label": "Treasury"},
+		{"address": "`+scam.Address.String()+`", "label": "Scam", "bad": true}
+	]`), 0644)
+	assert.NoError(t, err)
+
+	b, err := MakeAddressBook(WithAddressBookFile(p))
+	assert.NoError(t, err)
+
+	assert.Equal(t, treasury.Address.String()+" (Treasury)", b.Format(treasury.Address.String()))
+	assert.Equal(t, scam.Address.String()+" [!!!] KNOWN BAD ADDRESS: Scam", b.Format(scam.Address.String()))
+	assert.Equal(t, other.Address.String(), b.Format(other.Address.String()))
+
+	l, ok := b.Lookup(lookalike(treasury.Address.String()))
+	assert.True(t, ok)
+	assert.Equal(t, "Treasury", l.Resembles.Label)
+
+	assert.Equal(t, []string{treasury.Address.String()}, b.Addresses())
+
+	var nb *AddressBook
+	assert.Equal(t, other.Address.String(), nb.Format(other.Address.String()))
+
+	_, err = MakeAddressBook(WithAddressBookEntry(AddressBookEntry{Address: "bad", Label: "x"}))
+	assert.Error(t, err)
+}
+
+func TestAddressBookFormatTxn(t *testing.T) {
+	treasury := crypto.GenerateAccount()
+	acc := crypto.GenerateAccount()
+
+	b, err := MakeAddressBook(WithAddressBookEntry(AddressBookEntry{Address: treasury.Address.String(), Label: "Treasury"}))
+	assert.NoError(t, err)
+
+	txn := types.Transaction{
+		Type:   types.PaymentTx,
+		Header: types.Header{Sender: treasury.Address, FirstValid: 1, LastValid: 10},
+		PaymentTxnFields: types.PaymentTxnFields{
+			Receiver: acc.Address,
+		},
+	}
+
+	s := FormatTxn(txn, WithFormatAddressBook(b))
+	assert.Contains(t, s, "Sender: "+treasury.Address.String()+" (Treasury)")
+	assert.Contains(t, s, "Receiver: "+acc.Address.String()+"\n")
+
+	a, err := MakeRiskAnalyzer(WithRiskAddressBook(b))
+	assert.NoError(t, err)
+
+	codes := riskCodes(a.AnalyzeTxn(0, txn))
+	assert.Equal(t, map[string]Severity{"unknown-receiver": SeverityWarning}, codes)
+
+	scam := crypto.GenerateAccount()
+	err = WithAddressBookEntry(AddressBookEntry{Address: scam.Address.String(), Label: "Scam", Bad: true})(b)
+	assert.NoError(t, err)
+
+	txn.Receiver = scam.Address
+	codes = riskCodes(a.AnalyzeTxn(0, txn))
+	assert.Equal(t, SeverityCritical, codes["known-bad-address"])
+}
@@ -13,6 +13,11 @@ type AddressSource struct {
 
 	ma   *crypto.MultisigAccount
 	addr string
+
+	book *AddressBook
+
+	descPath string
+	desc     *MultisigDescriptor
 }
 
 type AddressSourceOption func(s *AddressSource)
@@ -29,6 +34,27 @@ func WithAddressThreshold(threshold int) AddressSourceOption {
 	}
 }
 
+// WithAddressBook shows the address book labels next to the printed addresses
+func WithAddressBook(b *AddressBook) AddressSourceOption {
+	return func(s *AddressSource) {
+		s.book = b
+	}
+}
+
+// WithAddressDescriptorFile loads the multisig account from a descriptor file instead of the address string
+func WithAddressDescriptorFile(path string) AddressSourceOption {
+	return func(s *AddressSource) {
+		s.descPath = path
+	}
+}
+
+// WithAddressDescriptor uses the multisig account of the descriptor instead of the address string
+func WithAddressDescriptor(d *MultisigDescriptor) AddressSourceOption {
+	return func(s *AddressSource) {
+		s.desc = d
+	}
+}
+
 func (s *AddressSource) Address() string {
 	return s.addr
 }
@@ -44,6 +70,19 @@ func MakeAddressSource(opts ...AddressSourceOption) (*AddressSource, error) {
 		opt(s)
 	}
 
+	if len(s.descPath) > 0 {
+		d, err := ReadMultisigDescriptorFile(s.descPath)
+		if err != nil {
+			return nil, err
+		}
+
+		s.desc = d
+	}
+
+	if s.desc != nil {
+		return s, s.loadDescriptor()
+	}
+
 	addrs, err := ParseAddrs(s.value, ",")
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse addresses")
@@ -54,6 +93,11 @@ func MakeAddressSource(opts ...AddressSourceOption) (*AddressSource, error) {
 	}
 
 	if len(addrs) > 1 {
+		err = ValidateMultisig(s.threshold, addrs)
+		if err != nil {
+			return nil, err
+		}
+
 		ma, err := crypto.MultisigAccountWithParams(1, uint8(s.threshold), addrs)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to build multisig account")
@@ -65,13 +109,42 @@ func MakeAddressSource(opts ...AddressSourceOption) (*AddressSource, error) {
 		}
 
 		s.ma = &ma
-
-		fmt.Println("Multisig address:", maddr)
 		s.addr = maddr.String()
+
+		fmt.Println("Multisig address:", s.book.Format(s.addr))
+
+		if s.book != nil {
+			for i, m := range addrs {
+				fmt.Printf("Member #%d: %s\n", i, s.book.Format(m.String()))
+			}
+		}
 	} else {
-		fmt.Println("Address:", addrs[0])
 		s.addr = addrs[0].String()
+
+		fmt.Println("Address:", s.book.Format(s.addr))
 	}
 
 	return s, nil
 }
+
+func (s *AddressSource) loadDescriptor() error {
+	if len(s.value) > 0 {
+		return errors.New("the multisig descriptor cannot be combined with an address")
+	}
+
+	ma, err := s.desc.MultisigAccount()
+	if err != nil {
+		return errors.Wrap(err, "failed to verify multisig descriptor")
+	}
+
+	s.ma = ma
+	s.addr = s.desc.Address
+
+	fmt.Printf("Multisig address: %s (%d of %d)\n", s.book.Format(s.addr), s.desc.Threshold, len(s.desc.Members))
+
+	for i, m := range s.desc.Members {
+		fmt.Printf("Member #%d: %s\n", i, s.book.Format(m.Address))
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package ams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/pkg/errors"
+)
+
+// AssetInfo holds the asset metadata needed to display amounts
+type AssetInfo struct {
+	ID       uint64 `json:"id" yaml:"id"`
+	UnitName string `json:"unit-name,omitempty" yaml:"unit-name,omitempty"`
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	Decimals uint64 `json:"decimals" yaml:"decimals"`
+
+	// Unknown is set for assets that do not exist, e.g. were destroyed
+	Unknown bool `json:"unknown,omitempty" yaml:"unknown,omitempty"`
+}
+
+// Label returns the terminal safe unit name falling back to the asset name
+func (a AssetInfo) Label() string {
+	label := a.Name
+	if len(a.UnitName) > 0 {
+		label = a.UnitName
+	}
+
+	label, _ = SanitizeText(label)
+	return label
+}
+
+type AssetResolver interface {
+	ResolveAsset(id uint64) (AssetInfo, error)
+}
+
+// AlgodAssetResolver looks up assets in algod and caches them in memory and optionally in a json file
+type AlgodAssetResolver struct {
+	ac *algod.Client
+
+	path string
+
+	mu    sync.Mutex
+	cache map[uint64]AssetInfo
+}
+
+type AlgodAssetResolverOption func(r *AlgodAssetResolver)
+
+// WithAlgodAssetResolverCacheFile keeps the resolved assets in a json file between runs
+func WithAlgodAssetResolverCacheFile(path string) AlgodAssetResolverOption {
+	return func(r *AlgodAssetResolver) {
+		r.path = path
+	}
+}
+
+func MakeAlgodAssetResolver(ac *algod.Client, opts ...AlgodAssetResolverOption) (*AlgodAssetResolver, error) {
+	r := &AlgodAssetResolver{
+		ac:    ac,
+		cache: map[uint64]AssetInfo{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if len(r.path) > 0 {
+		bs, err := os.ReadFile(r.path)
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return nil, errors.Wrap(err, "failed to read asset cache file")
+		default:
+			var assets []AssetInfo
+			err = json.Unmarshal(bs, &assets)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode asset cache file")
+			}
+
+			for _, a := range assets {
+				r.cache[a.ID] = a
+			}
+		}
+	}
+
+	return r, nil
+}
+
+func (r *AlgodAssetResolver) save() error {
+	var assets []AssetInfo
+	for _, a := range r.cache {
+		// unknown assets may be created later so they are not persisted
+		if !a.Unknown {
+			assets = append(assets, a)
+		}
+	}
+
+	bs, err := json.MarshalIndent(assets, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode asset cache")
+	}
+
+	err = os.WriteFile(r.path, bs, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write asset cache file")
+	}
+
+	return nil
+}
+
+func (r *AlgodAssetResolver) ResolveAsset(id uint64) (AssetInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a, ok := r.cache[id]; ok {
+		return a, nil
+	}
+
+	a := AssetInfo{
+		ID: id,
+	}
+
+	asset, err := r.ac.GetAssetByID(id).Do(context.Background())
+	switch {
+	case err == nil:
+		a.UnitName = asset.Params.UnitName
+		a.Name = asset.Params.Name
+		a.Decimals = asset.Params.Decimals
+		a.Unknown = asset.Deleted
+	// the sdk error types are plain error aliases so the status is matched in the message
+	case strings.Contains(err.Error(), "HTTP 404"):
+		a.Unknown = true
+	default:
+		return a, errors.Wrapf(err, "failed to get asset: %d", id)
+	}
+
+	r.cache[id] = a
+
+	if len(r.path) > 0 && !a.Unknown {
+		err = r.save()
+		if err != nil {
+			return a, err
+		}
+	}
+
+	return a, nil
+}
+
+// groupThousands inserts comma separators into a string of digits
+func groupThousands(digits string) string {
+	var out strings.Builder
+
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteByte(',')
+		}
+		out.WriteRune(c)
+	}
+
+	return out.String()
+}
+
+// FormatDecimal formats base units with the decimals of the asset keeping at least keep fraction digits
+func FormatDecimal(amount uint64, decimals uint64, keep int) string {
+	s := strconv.FormatUint(amount, 10)
+
+	if decimals == 0 {
+		return groupThousands(s)
+	}
+
+	d := int(decimals)
+	if len(s) <= d {
+		s = strings.Repeat("0", d-len(s)+1) + s
+	}
+
+	whole := s[:len(s)-d]
+	frac := s[len(s)-d:]
+
+	for len(frac) > keep && frac[len(frac)-1] == '0' {
+		frac = frac[:len(frac)-1]
+	}
+
+	if len(frac) == 0 {
+		return groupThousands(whole)
+	}
+
+	return groupThousands(whole) + "." + frac
+}
+
+// FormatAlgos formats microALGO as ALGO with six decimals
+func FormatAlgos(amount uint64) string {
+	return fmt.Sprintf("%s ALGO", FormatDecimal(amount, 6, 6))
+}
+
+// FormatAssetAmount formats base units of an asset, e.g. "1,250.50 USDC (ASA #31566704)"
+func FormatAssetAmount(amount uint64, a AssetInfo) string {
+	if a.Unknown {
+		return fmt.Sprintf("%d base units of ASA #%d [!] UNKNOWN OR DESTROYED ASSET", amount, a.ID)
+	}
+
+	keep := 2
+	if int(a.Decimals) < keep {
+		keep = int(a.Decimals)
+	}
+
+	return fmt.Sprintf("%s %s (ASA #%d)", FormatDecimal(amount, a.Decimals, keep), a.Label(), a.ID)
+}
+
+// ParseDecimal parses an amount with the decimals of the asset into base units, e.g. "1,250.5" with 6 decimals
+func ParseDecimal(s string, decimals uint64) (uint64, error) {
+	value := strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+
+	whole, frac, _ := strings.Cut(value, ".")
+	if len(whole) == 0 && len(frac) == 0 {
+		return 0, errors.Errorf("invalid amount: %s", s)
+	}
+
+	if uint64(len(frac)) > decimals {
+		return 0, errors.Errorf("too many decimal places in amount: %s, max: %d", s, decimals)
+	}
+
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, errors.Errorf("invalid amount: %s", s)
+		}
+	}
+
+	amount, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid amount: %s", s)
+	}
+
+	return amount, nil
+}
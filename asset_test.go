@@ -0,0 +1,108 @@
+package ams
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDecimal(t *testing.T) {
+	assert.Equal(t, "1,250.50", FormatDecimal(1250500000, 6, 2))
+	assert.Equal(t, "0.000001", FormatDecimal(1, 6, 2))
+	assert.Equal(t, "1,000,000", FormatDecimal(1000000, 0, 0))
+	assert.Equal(t, "12", FormatDecimal(12, 0, 0))
+	assert.Equal(t, "1.000000 ALGO", FormatAlgos(1000000))
+	assert.Equal(t, "0.001000 ALGO", FormatAlgos(1000))
+}
+
+func TestParseDecimal(t *testing.T) {
+	v, err := ParseDecimal("1,250.5", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1250500000), v)
+
+	v, err = ParseDecimal(".000001", 6)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), v)
+
+	v, err = ParseDecimal("12", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12), v)
+
+	_, err = ParseDecimal("1.5", 0)
+	assert.Error(t, err)
+
+	_, err = ParseDecimal("-1", 6)
+	assert.Error(t, err)
+
+	_, err = ParseDecimal("1.2.3", 6)
+	assert.Error(t, err)
+
+	_, err = ParseDecimal("99999999999999999999", 0)
+	assert.Error(t, err)
+}
+
+func TestAlgodAssetResolver(t *testing.T) {
+	var requests int
+
+	ac, _ := makeTestAlgod(t, map[string]http.HandlerFunc{
+		"/v2/assets/": func(w http.ResponseWriter, r *http.Request) {
+			requests++
+
+			if r.URL.Path != "/v2/assets/31566704" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message":"asset does not exist"}`))
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"index": 31566704,
+				"params": map[string]interface{}{
+					"creator":   "2UEQTE5QDNXPI7M3TU44G6SYKLFWLPQO7EBZM7K7MHMQQMFI4QJPLHQFHM",
+					"decimals":  6,
+					"total":     1000,
+					"name":      "USDC",
+					"unit-name": "USDC",
+				},
+			})
+		},
+	})
+
+	cache := filepath.Join(t.TempDir(), "assets.json")
+
+	r, err := MakeAlgodAssetResolver(ac, WithAlgodAssetResolverCacheFile(cache))
+	assert.NoError(t, err)
+
+	acc := crypto.GenerateAccount()
+
+	s := FormatTxn(types.Transaction{
+		Type:   types.AssetTransferTx,
+		Header: types.Header{Sender: acc.Address},
+		AssetTransferTxnFields: types.AssetTransferTxnFields{
+			XferAsset:     31566704,
+			AssetAmount:   1250500000,
+			AssetReceiver: acc.Address,
+		},
+	}, WithFormatAssetResolver(r))
+	assert.Contains(t, s, "Amount: 1,250.50 USDC (ASA #31566704)")
+
+	a, err := r.ResolveAsset(5)
+	assert.NoError(t, err)
+	assert.True(t, a.Unknown)
+	assert.Contains(t, FormatAssetAmount(10, a), "UNKNOWN OR DESTROYED")
+
+	// resolved assets are read from the cache file without asking algod
+	before := requests
+
+	r2, err := MakeAlgodAssetResolver(ac, WithAlgodAssetResolverCacheFile(cache))
+	assert.NoError(t, err)
+
+	a, err = r2.ResolveAsset(31566704)
+	assert.NoError(t, err)
+	assert.Equal(t, "USDC", a.UnitName)
+	assert.Equal(t, before, requests)
+}
@@ -0,0 +1,359 @@
+package ams
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+type TxnSpecType string
+
+const (
+	TxnSpecPay    TxnSpecType = "pay"
+	TxnSpecAxfer  TxnSpecType = "axfer"
+	TxnSpecOptIn  TxnSpecType = "optin"
+	TxnSpecOptOut TxnSpecType = "optout"
+	TxnSpecAcfg   TxnSpecType = "acfg"
+	TxnSpecAfrz   TxnSpecType = "afrz"
+	TxnSpecKeyreg TxnSpecType = "keyreg"
+	TxnSpecAppl   TxnSpecType = "appl"
+)
+
+// TxnSpecTypes lists the transaction types BuildTxn supports
+var TxnSpecTypes = []TxnSpecType{TxnSpecPay, TxnSpecAxfer, TxnSpecOptIn, TxnSpecOptOut, TxnSpecAcfg, TxnSpecAfrz, TxnSpecKeyreg, TxnSpecAppl}
+
+// TxnSpec describes a transaction to build from the suggested params. Amounts are in base units,
+// byte fields are base64 encoded in json.
+type TxnSpec struct {
+	Type   TxnSpecType `json:"type"`
+	Sender string      `json:"sender"`
+	// Fee is a flat fee, the suggested fee is used if 0
+	Fee uint64 `json:"fee,omitempty"`
+	// FirstValid overrides the suggested first valid round
+	FirstValid uint64 `json:"first-valid,omitempty"`
+	// Validity is the number of valid rounds, the suggested window is used if 0
+	Validity uint64 `json:"validity,omitempty"`
+	Note     string `json:"note,omitempty"`
+	Lease    []byte `json:"lease,omitempty"`
+	RekeyTo  string `json:"rekey-to,omitempty"`
+	// CloseTo is the close remainder to address of payments and the close assets to address of asset transfers
+	CloseTo string `json:"close-to,omitempty"`
+
+	Receiver string `json:"receiver,omitempty"`
+	Amount   uint64 `json:"amount,omitempty"`
+	Asset    uint64 `json:"asset,omitempty"`
+
+	// asset creation, an asset config without an asset id creates a new asset
+	Total         uint64 `json:"total,omitempty"`
+	Decimals      uint32 `json:"decimals,omitempty"`
+	DefaultFrozen bool   `json:"default-frozen,omitempty"`
+	UnitName      string `json:"unit-name,omitempty"`
+	AssetName     string `json:"asset-name,omitempty"`
+	URL           string `json:"url,omitempty"`
+	MetadataHash  []byte `json:"metadata-hash,omitempty"`
+	// the asset roles; nil roles of a new asset default to the sender, an empty string leaves the role unset
+	// and nil roles must be filled with the current ones before reconfiguring an existing asset
+	Manager  *string `json:"manager,omitempty"`
+	Reserve  *string `json:"reserve,omitempty"`
+	Freeze   *string `json:"freeze,omitempty"`
+	Clawback *string `json:"clawback,omitempty"`
+	Destroy  bool    `json:"destroy,omitempty"`
+
+	FreezeTarget string `json:"freeze-target,omitempty"`
+	Frozen       bool   `json:"frozen,omitempty"`
+
+	// keyreg without keys takes the account offline
+	VoteKey          string `json:"vote-key,omitempty"`
+	SelectionKey     string `json:"selection-key,omitempty"`
+	StateProofKey    string `json:"state-proof-key,omitempty"`
+	VoteFirst        uint64 `json:"vote-first,omitempty"`
+	VoteLast         uint64 `json:"vote-last,omitempty"`
+	KeyDilution      uint64 `json:"key-dilution,omitempty"`
+	Nonparticipation bool   `json:"nonparticipation,omitempty"`
+
+	// App is the called application, an application call without an app id creates a new app
+	App        uint64 `json:"app,omitempty"`
+	OnComplete string `json:"on-complete,omitempty"`
+	// Args are the application args, see ParseAppArg
+	Args            []string `json:"args,omitempty"`
+	Accounts        []string `json:"accounts,omitempty"`
+	ForeignApps     []uint64 `json:"foreign-apps,omitempty"`
+	ForeignAssets   []uint64 `json:"foreign-assets,omitempty"`
+	ApprovalProgram []byte   `json:"approval-program,omitempty"`
+	ClearProgram    []byte   `json:"clear-program,omitempty"`
+	GlobalInts      uint64   `json:"global-ints,omitempty"`
+	GlobalBytes     uint64   `json:"global-bytes,omitempty"`
+	LocalInts       uint64   `json:"local-ints,omitempty"`
+	LocalBytes      uint64   `json:"local-bytes,omitempty"`
+	ExtraPages      uint32   `json:"extra-pages,omitempty"`
+}
+
+// ReadTxnSpecFile reads a json file with a single spec or an array of specs of a group
+func ReadTxnSpecFile(path string) ([]TxnSpec, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read transaction spec file")
+	}
+
+	trimmed := bytes.TrimSpace(bs)
+
+	var specs []TxnSpec
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &specs)
+	} else {
+		var spec TxnSpec
+		err = json.Unmarshal(trimmed, &spec)
+		specs = append(specs, spec)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode transaction spec file")
+	}
+
+	return specs, nil
+}
+
+// ParseAppArg parses an application arg in the goal format: str:text, int:123, addr:ADDRESS, b64:data or hex:data
+func ParseAppArg(s string) ([]byte, error) {
+	kind, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, errors.Errorf("invalid app arg, expected <kind>:<value>: %s", s)
+	}
+
+	switch kind {
+	case "str":
+		return []byte(value), nil
+	case "int":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid int app arg: %s", value)
+		}
+
+		bs := make([]byte, 8)
+		binary.BigEndian.PutUint64(bs, v)
+
+		return bs, nil
+	case "addr":
+		addr, err := types.DecodeAddress(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid addr app arg: %s", value)
+		}
+
+		return addr[:], nil
+	case "b64":
+		bs, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid b64 app arg: %s", value)
+		}
+
+		return bs, nil
+	case "hex":
+		bs, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid hex app arg: %s", value)
+		}
+
+		return bs, nil
+	default:
+		return nil, errors.Errorf("unknown app arg kind: %s", kind)
+	}
+}
+
+// ParseOnCompletion parses the goal style on-completion names, noop by default
+func ParseOnCompletion(s string) (types.OnCompletion, error) {
+	switch s {
+	case "", "noop":
+		return types.NoOpOC, nil
+	case "optin":
+		return types.OptInOC, nil
+	case "closeout":
+		return types.CloseOutOC, nil
+	case "clear":
+		return types.ClearStateOC, nil
+	case "update":
+		return types.UpdateApplicationOC, nil
+	case "delete":
+		return types.DeleteApplicationOC, nil
+	default:
+		return 0, errors.Errorf("unknown on-completion: %s", s)
+	}
+}
+
+func roleValue(role *string, name string) (string, error) {
+	if role == nil {
+		return "", errors.Errorf("missing asset %s address", name)
+	}
+
+	return *role, nil
+}
+
+func roleOrDefault(role *string, def string) string {
+	if role == nil {
+		return def
+	}
+
+	return *role
+}
+
+func (s TxnSpec) buildAcfg(note []byte, sp types.SuggestedParams) (types.Transaction, error) {
+	switch {
+	case s.Asset == 0:
+		manager := roleOrDefault(s.Manager, s.Sender)
+		reserve := roleOrDefault(s.Reserve, s.Sender)
+		freeze := roleOrDefault(s.Freeze, s.Sender)
+		clawback := roleOrDefault(s.Clawback, s.Sender)
+
+		return future.MakeAssetCreateTxn(s.Sender, note, sp, s.Total, s.Decimals, s.DefaultFrozen,
+			manager, reserve, freeze, clawback, s.UnitName, s.AssetName, s.URL, string(s.MetadataHash))
+	case s.Destroy:
+		return future.MakeAssetDestroyTxn(s.Sender, note, sp, s.Asset)
+	}
+
+	var roles [4]string
+	for i, r := range []struct {
+		v    *string
+		name string
+	}{{s.Manager, "manager"}, {s.Reserve, "reserve"}, {s.Freeze, "freeze"}, {s.Clawback, "clawback"}} {
+		v, err := roleValue(r.v, r.name)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+
+		roles[i] = v
+	}
+
+	return future.MakeAssetConfigTxn(s.Sender, note, sp, s.Asset, roles[0], roles[1], roles[2], roles[3], false)
+}
+
+func (s TxnSpec) buildAppl(note []byte, sp types.SuggestedParams) (types.Transaction, error) {
+	oc, err := ParseOnCompletion(s.OnComplete)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	var args [][]byte
+	for _, a := range s.Args {
+		bs, err := ParseAppArg(a)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+
+		args = append(args, bs)
+	}
+
+	sender, err := types.DecodeAddress(s.Sender)
+	if err != nil {
+		return types.Transaction{}, errors.Wrapf(err, "invalid sender address: %s", s.Sender)
+	}
+
+	global := types.StateSchema{NumUint: s.GlobalInts, NumByteSlice: s.GlobalBytes}
+	local := types.StateSchema{NumUint: s.LocalInts, NumByteSlice: s.LocalBytes}
+
+	return future.MakeApplicationCallTxWithBoxes(s.App, args, s.Accounts, s.ForeignApps, s.ForeignAssets, nil, oc,
+		s.ApprovalProgram, s.ClearProgram, global, local, s.ExtraPages, sp, sender, note, types.Digest{}, [32]byte{}, types.Address{})
+}
+
+func (s TxnSpec) build(note []byte, sp types.SuggestedParams) (types.Transaction, error) {
+	switch s.Type {
+	case TxnSpecPay:
+		return future.MakePaymentTxn(s.Sender, s.Receiver, s.Amount, note, s.CloseTo, sp)
+	case TxnSpecAxfer:
+		return future.MakeAssetTransferTxn(s.Sender, s.Receiver, s.Amount, note, sp, s.CloseTo, s.Asset)
+	case TxnSpecOptIn:
+		return future.MakeAssetAcceptanceTxn(s.Sender, note, sp, s.Asset)
+	case TxnSpecOptOut:
+		if len(s.CloseTo) == 0 {
+			return types.Transaction{}, errors.New("asset opt-out requires a close-to address")
+		}
+
+		return future.MakeAssetTransferTxn(s.Sender, s.CloseTo, 0, note, sp, s.CloseTo, s.Asset)
+	case TxnSpecAcfg:
+		return s.buildAcfg(note, sp)
+	case TxnSpecAfrz:
+		return future.MakeAssetFreezeTxn(s.Sender, note, sp, s.Asset, s.FreezeTarget, s.Frozen)
+	case TxnSpecKeyreg:
+		return future.MakeKeyRegTxnWithStateProofKey(s.Sender, note, sp, s.VoteKey, s.SelectionKey, s.StateProofKey,
+			s.VoteFirst, s.VoteLast, s.KeyDilution, s.Nonparticipation)
+	case TxnSpecAppl:
+		return s.buildAppl(note, sp)
+	default:
+		return types.Transaction{}, errors.Errorf("unknown transaction type: %s", s.Type)
+	}
+}
+
+// BuildTxn makes the transaction of the spec using the suggested params
+func BuildTxn(s TxnSpec, sp types.SuggestedParams) (types.Transaction, error) {
+	if len(s.Sender) == 0 {
+		return types.Transaction{}, errors.New("missing sender")
+	}
+
+	if s.FirstValid > 0 {
+		window := sp.LastRoundValid - sp.FirstRoundValid
+		sp.FirstRoundValid = types.Round(s.FirstValid)
+		sp.LastRoundValid = sp.FirstRoundValid + window
+	}
+
+	if s.Validity > 0 {
+		sp.LastRoundValid = sp.FirstRoundValid + types.Round(s.Validity) - 1
+	}
+
+	if s.Fee > 0 {
+		sp.FlatFee = true
+		sp.Fee = types.MicroAlgos(s.Fee)
+	}
+
+	var note []byte
+	if len(s.Note) > 0 {
+		note = []byte(s.Note)
+	}
+
+	txn, err := s.build(note, sp)
+	if err != nil {
+		return types.Transaction{}, errors.Wrapf(err, "failed to make %s transaction", s.Type)
+	}
+
+	if len(s.Lease) > 0 {
+		if len(s.Lease) != len(txn.Lease) {
+			return types.Transaction{}, errors.Errorf("invalid lease length - got: %d, expected: %d", len(s.Lease), len(txn.Lease))
+		}
+
+		copy(txn.Lease[:], s.Lease)
+	}
+
+	if len(s.RekeyTo) > 0 {
+		txn.RekeyTo, err = types.DecodeAddress(s.RekeyTo)
+		if err != nil {
+			return types.Transaction{}, errors.Wrapf(err, "invalid rekey-to address: %s", s.RekeyTo)
+		}
+	}
+
+	if !sp.FlatFee {
+		// the lease and the rekey address make the transaction larger
+		size, err := transaction.EstimateSize(txn)
+		if err != nil {
+			return types.Transaction{}, errors.Wrap(err, "failed to estimate transaction size")
+		}
+
+		fee := uint64(sp.Fee) * size
+		if fee < sp.MinFee {
+			fee = sp.MinFee
+		}
+		if fee < transaction.MinTxnFee {
+			fee = transaction.MinTxnFee
+		}
+
+		txn.Fee = types.MicroAlgos(fee)
+	}
+
+	return txn, nil
+}
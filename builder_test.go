@@ -0,0 +1,135 @@
+package ams
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSuggestedParams returns the suggested params of round 100 with the minimum fee
+func testSuggestedParams() types.SuggestedParams {
+	return types.SuggestedParams{
+		Fee:             0,
+		MinFee:          1000,
+		FirstRoundValid: 100,
+		LastRoundValid:  1100,
+		GenesisID:       "testnet-v1.0",
+		GenesisHash:     make([]byte, 32),
+	}
+}
+
+func TestBuildTxnPay(t *testing.T) {
+	sender := crypto.GenerateAccount().Address.String()
+	receiver := crypto.GenerateAccount().Address.String()
+	rekey := crypto.GenerateAccount().Address.String()
+
+	lease := make([]byte, 32)
+	lease[0] = 1
+
+	txn, err := BuildTxn(TxnSpec{
+		Type:     TxnSpecPay,
+		Sender:   sender,
+		Receiver: receiver,
+		Amount:   1500000,
+		Note:     "hello",
+		Lease:    lease,
+		RekeyTo:  rekey,
+		Validity: 10,
+	}, testSuggestedParams())
+	assert.NoError(t, err)
+
+	assert.Equal(t, types.PaymentTx, txn.Type)
+	assert.Equal(t, types.MicroAlgos(1500000), txn.Amount)
+	assert.Equal(t, receiver, txn.Receiver.String())
+	assert.Equal(t, rekey, txn.RekeyTo.String())
+	assert.Equal(t, byte(1), txn.Lease[0])
+	assert.Equal(t, []byte("hello"), txn.Note)
+	assert.Equal(t, types.Round(100), txn.FirstValid)
+	assert.Equal(t, types.Round(109), txn.LastValid)
+	assert.Equal(t, types.MicroAlgos(1000), txn.Fee)
+
+	_, err = BuildTxn(TxnSpec{Type: TxnSpecPay, Sender: sender, Receiver: receiver, Lease: []byte{1}}, testSuggestedParams())
+	assert.Error(t, err)
+}
+
+func TestBuildTxnAssets(t *testing.T) {
+	sender := crypto.GenerateAccount().Address.String()
+	creator := crypto.GenerateAccount().Address.String()
+	sp := testSuggestedParams()
+
+	txn, err := BuildTxn(TxnSpec{Type: TxnSpecOptIn, Sender: sender, Asset: 5}, sp)
+	assert.NoError(t, err)
+	assert.Equal(t, types.AssetTransferTx, txn.Type)
+	assert.Equal(t, sender, txn.AssetReceiver.String())
+
+	_, err = BuildTxn(TxnSpec{Type: TxnSpecOptOut, Sender: sender, Asset: 5}, sp)
+	assert.Error(t, err)
+
+	txn, err = BuildTxn(TxnSpec{Type: TxnSpecOptOut, Sender: sender, Asset: 5, CloseTo: creator}, sp)
+	assert.NoError(t, err)
+	assert.Equal(t, creator, txn.AssetCloseTo.String())
+
+	txn, err = BuildTxn(TxnSpec{Type: TxnSpecAcfg, Sender: sender, Total: 1000, Decimals: 2, UnitName: "TST"}, sp)
+	assert.NoError(t, err)
+	assert.Equal(t, sender, txn.AssetParams.Manager.String())
+	assert.Equal(t, uint32(2), txn.AssetParams.Decimals)
+
+	_, err = BuildTxn(TxnSpec{Type: TxnSpecAcfg, Sender: sender, Asset: 5}, sp)
+	assert.Error(t, err)
+
+	empty := ""
+	txn, err = BuildTxn(TxnSpec{Type: TxnSpecAcfg, Sender: sender, Asset: 5, Manager: &sender, Reserve: &empty, Freeze: &empty, Clawback: &empty}, sp)
+	assert.NoError(t, err)
+	assert.Equal(t, sender, txn.AssetParams.Manager.String())
+	assert.True(t, txn.AssetParams.Clawback.IsZero())
+
+	txn, err = BuildTxn(TxnSpec{Type: TxnSpecAfrz, Sender: sender, Asset: 5, FreezeTarget: creator, Frozen: true}, sp)
+	assert.NoError(t, err)
+	assert.True(t, txn.AssetFrozen)
+}
+
+func TestBuildTxnAppl(t *testing.T) {
+	sender := crypto.GenerateAccount().Address.String()
+
+	txn, err := BuildTxn(TxnSpec{
+		Type:       TxnSpecAppl,
+		Sender:     sender,
+		App:        123,
+		OnComplete: "optin",
+		Args:       []string{"str:hi", "int:1", "hex:ff"},
+	}, testSuggestedParams())
+	assert.NoError(t, err)
+	assert.Equal(t, types.OptInOC, txn.OnCompletion)
+	assert.Equal(t, [][]byte{[]byte("hi"), {0, 0, 0, 0, 0, 0, 0, 1}, {0xff}}, txn.ApplicationArgs)
+
+	_, err = BuildTxn(TxnSpec{Type: TxnSpecAppl, Sender: sender, Args: []string{"bad"}}, testSuggestedParams())
+	assert.Error(t, err)
+
+	_, err = BuildTxn(TxnSpec{Type: "unknown", Sender: sender}, testSuggestedParams())
+	assert.Error(t, err)
+}
+
+func TestReadTxnSpecFile(t *testing.T) {
+	dir := t.TempDir()
+
+	single := filepath.Join(dir, "single.json")
+	assert.NoError(t, os.WriteFile(single, []byte(`{"type": "pay", "amount": 5}`), 0644))
+
+	specs, err := ReadTxnSpecFile(single)
+	assert.NoError(t, err)
+	assert.Len(t, specs, 1)
+	assert.Equal(t, uint64(5), specs[0].Amount)
+
+	group := filepath.Join(dir, "group.json")
+	assert.NoError(t, os.WriteFile(group, []byte(`[{"type": "pay"}, {"type": "axfer", "asset": 7, "manager": ""}]`), 0644))
+
+	specs, err = ReadTxnSpecFile(group)
+	assert.NoError(t, err)
+	assert.Len(t, specs, 2)
+	assert.Equal(t, TxnSpecAxfer, specs[1].Type)
+	assert.NotNil(t, specs[1].Manager)
+}
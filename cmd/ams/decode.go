@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/ams"
+	"github.com/pkg/errors"
+)
+
+type decodeArgs struct {
+	Output        string
+	Algod         string
+	AlgodToken    string
+	ResolveAssets bool
+	AssetCache    string
+	AbiDir        string
+	AddressBook   string
+	Risk          bool
+	Validity      bool
+}
+
+func runDecode(argv []string) error {
+	var a decodeArgs
+
+	fs := flag.NewFlagSet("decode", flag.ExitOnError)
+	fs.StringVar(&a.Output, "output", "text", "output format: text, json or yaml")
+	fs.StringVar(&a.Algod, "algod", "https://mainnet-api.algonode.cloud", "algod node address")
+	fs.StringVar(&a.AlgodToken, "algod-token", "", "algod node token")
+	fs.BoolVar(&a.ResolveAssets, "resolve-assets", false, "show asset amounts with decimals and unit names from algod")
+	fs.StringVar(&a.AssetCache, "asset-cache", "", "asset metadata cache file")
+	fs.StringVar(&a.AbiDir, "abi-dir", "", "directory of ARC-4 contract or ARC-56 app spec json files used to decode app calls")
+	fs.StringVar(&a.AddressBook, "address-book", "", "address book json file with address labels")
+	fs.BoolVar(&a.Validity, "validity", false, "show the validity windows in wall-clock time using the algod status")
+	fs.BoolVar(&a.Risk, "risk", true, "include the risk findings")
+	fs.Parse(argv)
+
+	output, err := ams.ParseOutputFormat(a.Output)
+	if err != nil {
+		return err
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return errors.New("missing transaction files")
+	}
+
+	var stxs []types.SignedTxn
+
+	for _, p := range paths {
+		txs, err := readSignedTxnsFile(p)
+		if err != nil {
+			return err
+		}
+
+		stxs = append(stxs, txs...)
+	}
+
+	book, err := ams.MakeAddressBook(ams.WithAddressBookFile(a.AddressBook))
+	if err != nil {
+		return errors.Wrap(err, "failed to load address book")
+	}
+
+	opts := []ams.FormatOption{
+		ams.WithFormatAddressBook(book),
+	}
+
+	ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
+	if err != nil {
+		return errors.Wrap(err, "failed to make algod client")
+	}
+
+	if a.ResolveAssets {
+		ar, err := ams.MakeAlgodAssetResolver(ac,
+			ams.WithAlgodAssetResolverCacheFile(a.AssetCache),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to make asset resolver")
+		}
+
+		opts = append(opts, ams.WithFormatAssetResolver(ar))
+	}
+
+	if len(a.AbiDir) > 0 {
+		reg, err := ams.MakeABIRegistry(ams.WithABIRegistryDir(a.AbiDir))
+		if err != nil {
+			return errors.Wrap(err, "failed to load abi specs")
+		}
+
+		opts = append(opts, ams.WithFormatABIRegistry(reg))
+	}
+
+	if a.Validity {
+		clock, err := ams.FetchRoundClock(ac, ams.DefaultBlockTimeSamples)
+		if err != nil {
+			return errors.Wrap(err, "failed to estimate round times")
+		}
+
+		opts = append(opts, ams.WithFormatRoundClock(clock))
+	}
+
+	if a.Risk {
+		risk, err := ams.MakeRiskAnalyzer(ams.WithRiskAddressBook(book))
+		if err != nil {
+			return errors.Wrap(err, "failed to make risk analyzer")
+		}
+
+		opts = append(opts, ams.WithFormatRiskAnalyzer(risk))
+	}
+
+	text, err := ams.DecodeTxnGroup(stxs, opts...).Render(output)
+	if err != nil {
+		return errors.Wrap(err, "failed to render transactions")
+	}
+
+	fmt.Print(text)
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	Name  string
+	Usage string
+	Run   func(argv []string) error
+}
+
+var commands = []command{
+	{Name: "decode", Usage: "show transaction files as text, json or yaml", Run: runDecode},
+	{Name: "msig", Usage: "multisig descriptors and signed transaction files: merge, status, create, inspect", Run: runMsig},
+	{Name: "refresh", Usage: "re-stamp validity, genesis and fees of unsigned transaction files", Run: runRefresh},
+	{Name: "submit", Usage: "send signed transaction files to algod as one group", Run: runSubmit},
+	{Name: "txn", Usage: "build unsigned transaction files: pay, axfer, optin, optout, acfg, afrz, keyreg, appl, spec", Run: runTxn},
+}
+
+func usage() {
+	fmt.Println("Usage: ams <command> [arguments]")
+	fmt.Println()
+	fmt.Println("Commands:")
+
+	for _, c := range commands {
+		fmt.Printf("  %-10s %s\n", c.Name, c.Usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.Name == os.Args[1] {
+			err := c.Run(os.Args[2:])
+			if err != nil {
+				panic(err)
+			}
+			return
+		}
+	}
+
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/ams"
+	"github.com/pkg/errors"
+)
+
+func runMsig(argv []string) error {
+	if len(argv) == 0 {
+		return errors.New("missing msig command: merge, status, create, inspect")
+	}
+
+	switch argv[0] {
+	case "merge":
+		return runMsigMerge(argv[1:])
+	case "status":
+		return runMsigStatus(argv[1:])
+	case "create":
+		return runMsigCreate(argv[1:])
+	case "inspect":
+		return runMsigInspect(argv[1:])
+	default:
+		return errors.Errorf("unknown msig command: %s", argv[0])
+	}
+}
+
+func readSignedTxnsFile(path string) ([]types.SignedTxn, error) {
+	txs, err := ams.ReadTxnFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read transactions file: %s", path)
+	}
+
+	return ams.InputSignedTxns(txs), nil
+}
+
+type msigMergeArgs struct {
+	Out  string
+	Msig string
+}
+
+func runMsigMerge(argv []string) error {
+	var a msigMergeArgs
+
+	fs := flag.NewFlagSet("msig merge", flag.ExitOnError)
+	fs.StringVar(&a.Out, "out", "", "merged transactions output file")
+	fs.StringVar(&a.Msig, "msig", "", "multisig account used to convert single signatures: threshold:addr1,addr2,..")
+	fs.Parse(argv)
+
+	if len(a.Out) == 0 {
+		return errors.New("missing output file")
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return errors.New("missing partial transaction files")
+	}
+
+	var ma *crypto.MultisigAccount
+
+	if len(a.Msig) > 0 {
+		var err error
+		ma, err = ams.ParseMultisig(a.Msig)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse multisig")
+		}
+	}
+
+	var partials [][]types.SignedTxn
+
+	for _, p := range paths {
+		stxs, err := readSignedTxnsFile(p)
+		if err != nil {
+			return err
+		}
+
+		partials = append(partials, stxs)
+	}
+
+	merged, err := ams.MergePartials(partials, ma)
+	if err != nil {
+		return errors.Wrap(err, "failed to merge partial transactions")
+	}
+
+	err = os.WriteFile(a.Out, ams.EncodeSignedTxns(merged), 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write merged transactions")
+	}
+
+	fmt.Printf("Merged %d files into: %s\n", len(paths), a.Out)
+
+	printMsigStatus(merged)
+
+	return nil
+}
+
+type msigStatusArgs struct {
+	Json bool
+}
+
+type msigTxnStatus struct {
+	Index    int                 `json:"index"`
+	TxID     string              `json:"txid"`
+	Kind     string              `json:"kind"`
+	Multisig *ams.MultisigStatus `json:"multisig,omitempty"`
+	Verified bool                `json:"verified"`
+	Ready    bool                `json:"ready"`
+}
+
+func getMsigTxnStatus(i int, stx types.SignedTxn) msigTxnStatus {
+	st := msigTxnStatus{
+		Index: i,
+		TxID:  crypto.TransactionIDString(stx.Txn),
+	}
+
+	switch {
+	case !stx.Msig.Blank():
+		st.Kind = "multisig"
+
+		ms, err := ams.GetMultisigStatus(stx)
+		if err == nil {
+			st.Multisig = ms
+			st.Verified = ms.Invalid == 0
+			st.Ready = ms.Ready
+		}
+	case stx.Sig != (types.Signature{}):
+		st.Kind = "single"
+		st.Verified = ams.VerifySig(stx)
+		st.Ready = st.Verified
+	case !stx.Lsig.Blank():
+		// the logic is evaluated by the network only
+		st.Kind = "logicsig"
+		st.Ready = true
+	default:
+		st.Kind = "unsigned"
+	}
+
+	return st
+}
+
+func printMsigStatus(stxs []types.SignedTxn) bool {
+	ready := true
+
+	for i, stx := range stxs {
+		st := getMsigTxnStatus(i, stx)
+
+		fmt.Printf("Transaction #%d: %s\n", i, st.TxID)
+
+		switch st.Kind {
+		case "multisig":
+			ms := st.Multisig
+			if ms == nil {
+				fmt.Println("  Multisig: invalid")
+				break
+			}
+
+			fmt.Printf("  Multisig: %s (version: %d, threshold: %d / %d)\n", ms.Address, ms.Version, ms.Threshold, len(ms.Subsigs))
+
+			for _, ss := range ms.Subsigs {
+				switch {
+				case ss.Signed && ss.Valid:
+					fmt.Printf("  [x] %s\n", ss.Address)
+				case ss.Signed:
+					fmt.Printf("  [!] %s INVALID SIGNATURE\n", ss.Address)
+				default:
+					fmt.Printf("  [ ] %s\n", ss.Address)
+				}
+			}
+
+			if ms.Missing > 0 {
+				fmt.Printf("  Signatures: %d valid, need %d more\n", ms.Valid, ms.Missing)
+			} else {
+				fmt.Printf("  Signatures: %d valid\n", ms.Valid)
+			}
+		case "single":
+			if st.Verified {
+				fmt.Println("  Signed with a single signature")
+			} else {
+				fmt.Println("  Signed with a single signature: INVALID SIGNATURE")
+			}
+		case "logicsig":
+			fmt.Println("  Signed with a logic signature (unverified)")
+		default:
+			fmt.Println("  Unsigned")
+		}
+
+		if !st.Ready {
+			ready = false
+		}
+	}
+
+	if ready {
+		fmt.Println("READY TO SUBMIT")
+	} else {
+		fmt.Println("NOT READY - missing or invalid signatures")
+	}
+
+	return ready
+}
+
+func runMsigStatus(argv []string) error {
+	var a msigStatusArgs
+
+	fs := flag.NewFlagSet("msig status", flag.ExitOnError)
+	fs.BoolVar(&a.Json, "json", false, "print status as json")
+	fs.Parse(argv)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return errors.New("missing transaction files")
+	}
+
+	for _, p := range paths {
+		stxs, err := readSignedTxnsFile(p)
+		if err != nil {
+			return err
+		}
+
+		if a.Json {
+			var sts []msigTxnStatus
+			for i, stx := range stxs {
+				sts = append(sts, getMsigTxnStatus(i, stx))
+			}
+
+			bs, err := json.MarshalIndent(map[string]interface{}{
+				"file":         p,
+				"transactions": sts,
+			}, "", "  ")
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal status")
+			}
+
+			fmt.Println(string(bs))
+			continue
+		}
+
+		fmt.Println("File:", p)
+		printMsigStatus(stxs)
+	}
+
+	return nil
+}
+
+type msigCreateArgs struct {
+	Out       string
+	Label     string
+	Threshold uint
+	Members   listArg
+}
+
+func runMsigCreate(argv []string) error {
+	var a msigCreateArgs
+
+	fs := flag.NewFlagSet("msig create", flag.ExitOnError)
+	fs.StringVar(&a.Out, "out", "", "multisig descriptor output file")
+	fs.StringVar(&a.Label, "label", "", "multisig account label")
+	fs.UintVar(&a.Threshold, "threshold", 0, "number of signatures required")
+	fs.Var(&a.Members, "member", "multisig member in order: addr[:label] (repeatable)")
+	fs.Parse(argv)
+
+	if len(a.Out) == 0 {
+		return errors.New("missing output file")
+	}
+
+	var members []ams.MultisigMember
+
+	for _, m := range a.Members {
+		addr, label, _ := strings.Cut(m, ":")
+		members = append(members, ams.MultisigMember{
+			Address: strings.TrimSpace(addr),
+			Label:   strings.TrimSpace(label),
+		})
+	}
+
+	d, err := ams.MakeMultisigDescriptor(int(a.Threshold), members, a.Label)
+	if err != nil {
+		return errors.Wrap(err, "failed to make multisig descriptor")
+	}
+
+	err = d.Write(a.Out)
+	if err != nil {
+		return err
+	}
+
+	d.Print()
+
+	fmt.Println("Multisig descriptor written to:", a.Out)
+
+	return nil
+}
+
+func runMsigInspect(argv []string) error {
+	fs := flag.NewFlagSet("msig inspect", flag.ExitOnError)
+	fs.Parse(argv)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return errors.New("missing multisig descriptor files")
+	}
+
+	for _, p := range paths {
+		d, err := ams.ReadMultisigDescriptorFile(p)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("File:", p)
+		d.Print()
+		fmt.Println("Descriptor verified")
+	}
+
+	return nil
+}
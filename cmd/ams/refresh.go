@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/ams"
+	"github.com/pkg/errors"
+)
+
+type refreshArgs struct {
+	Algod      string
+	AlgodToken string
+	Out        string
+}
+
+func runRefresh(argv []string) error {
+	var a refreshArgs
+
+	fs := flag.NewFlagSet("refresh", flag.ExitOnError)
+	fs.StringVar(&a.Algod, "algod", "https://mainnet-api.algonode.cloud", "algod node address")
+	fs.StringVar(&a.AlgodToken, "algod-token", "", "algod node token")
+	fs.StringVar(&a.Out, "out", "", "refreshed transactions output file")
+	fs.Parse(argv)
+
+	if len(a.Out) == 0 {
+		return errors.New("missing output file")
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return errors.New("missing transaction files")
+	}
+
+	var stxs []types.SignedTxn
+
+	for _, p := range paths {
+		txs, err := readSignedTxnsFile(p)
+		if err != nil {
+			return err
+		}
+
+		stxs = append(stxs, txs...)
+	}
+
+	ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
+	if err != nil {
+		return errors.Wrap(err, "failed to make algod client")
+	}
+
+	sp, err := ac.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to get suggested params")
+	}
+
+	changes, err := ams.Refresh(stxs, sp)
+	if err != nil {
+		return errors.Wrap(err, "failed to refresh transactions")
+	}
+
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+
+	err = os.WriteFile(a.Out, ams.EncodeSignedTxns(stxs), 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write refreshed transactions")
+	}
+
+	fmt.Println("Written refreshed transactions:", a.Out)
+
+	return nil
+}
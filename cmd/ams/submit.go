@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/ams"
+	"github.com/pkg/errors"
+)
+
+type submitArgs struct {
+	Algod      string
+	AlgodToken string
+
+	Wait     uint64
+	Json     bool
+	Simulate bool
+}
+
+func runSubmit(argv []string) error {
+	var a submitArgs
+
+	fs := flag.NewFlagSet("submit", flag.ExitOnError)
+	fs.StringVar(&a.Algod, "algod", "https://mainnet-api.algonode.cloud", "algod node address")
+	fs.StringVar(&a.AlgodToken, "algod-token", "", "algod node token")
+	fs.Uint64Var(&a.Wait, "wait", ams.DefaultWaitRounds, "rounds to wait for confirmation (0 - no waiting)")
+	fs.BoolVar(&a.Json, "json", false, "print the result as json")
+	fs.BoolVar(&a.Simulate, "simulate", false, "simulate the group before sending it")
+	fs.Parse(argv)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		return errors.New("missing signed transaction files")
+	}
+
+	var stxs []types.SignedTxn
+
+	for _, p := range paths {
+		txs, err := readSignedTxnsFile(p)
+		if err != nil {
+			return err
+		}
+
+		stxs = append(stxs, txs...)
+	}
+
+	var signed [][]byte
+
+	for i, stx := range stxs {
+		if !ams.IsFullySigned(stx) {
+			return errors.Errorf("transaction #%d is not fully signed", i)
+		}
+
+		signed = append(signed, msgpack.Encode(stx))
+	}
+
+	ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
+	if err != nil {
+		return errors.Wrap(err, "failed to make algod client")
+	}
+
+	if a.Simulate {
+		sim, err := ams.MakeSimulator(a.Algod, a.AlgodToken)
+		if err != nil {
+			return errors.Wrap(err, "failed to make simulator")
+		}
+
+		sres, err := sim.Simulate(stxs)
+		if err != nil {
+			return errors.Wrap(err, "failed to simulate transactions")
+		}
+
+		if !a.Json {
+			sres.Print()
+		}
+
+		if !sres.WouldSucceed {
+			return errors.Errorf("simulation failed - transaction: %d, message: %s", sres.FailedIndex(), sres.FailureMessage)
+		}
+	}
+
+	if !a.Json {
+		fmt.Println("Sending signed transactions:", len(signed))
+	}
+
+	res, err := ams.SubmitGroup(ac, signed, a.Wait)
+	if res != nil {
+		perr := ams.PrintSubmitResult(res, a.Json)
+		if perr != nil {
+			return perr
+		}
+	}
+
+	return err
+}
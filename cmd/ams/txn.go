@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/ams"
+	"github.com/pkg/errors"
+)
+
+type listArg []string
+
+func (l *listArg) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listArg) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+type uintListArg []uint64
+
+func (l *uintListArg) String() string {
+	res := make([]string, len(*l))
+	for i, v := range *l {
+		res[i] = strconv.FormatUint(v, 10)
+	}
+	return strings.Join(res, ",")
+}
+
+func (l *uintListArg) Set(value string) error {
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*l = append(*l, v)
+	return nil
+}
+
+type txnArgs struct {
+	Algod      string
+	AlgodToken string
+	Out        string
+
+	Lease    string
+	Approval string
+	Clear    string
+
+	Manager  string
+	Reserve  string
+	Freeze   string
+	Clawback string
+
+	Args          listArg
+	Accounts      listArg
+	ForeignApps   uintListArg
+	ForeignAssets uintListArg
+}
+
+func txnTypesUsage() string {
+	names := make([]string, len(ams.TxnSpecTypes))
+	for i, t := range ams.TxnSpecTypes {
+		names[i] = string(t)
+	}
+
+	return strings.Join(names, ", ") + ", spec"
+}
+
+// addTxnFlags registers the flags of the transaction type
+func addTxnFlags(fs *flag.FlagSet, typ ams.TxnSpecType, s *ams.TxnSpec, a *txnArgs) {
+	fs.StringVar(&s.Sender, "sender", "", "sender address")
+	fs.Uint64Var(&s.Fee, "fee", 0, "flat fee in microALGO (0 - suggested fee)")
+	fs.Uint64Var(&s.FirstValid, "first-valid", 0, "first valid round (0 - suggested round)")
+	fs.Uint64Var(&s.Validity, "validity", 0, "number of valid rounds (0 - suggested window)")
+	fs.StringVar(&s.Note, "note", "", "transaction note")
+	fs.StringVar(&a.Lease, "lease", "", "base64 encoded 32 byte lease")
+	fs.StringVar(&s.RekeyTo, "rekey-to", "", "rekey the sender to the address")
+
+	switch typ {
+	case ams.TxnSpecPay:
+		fs.StringVar(&s.Receiver, "receiver", "", "receiver address")
+		fs.Uint64Var(&s.Amount, "amount", 0, "amount in microALGO")
+		fs.StringVar(&s.CloseTo, "close-to", "", "close the sender account to the address")
+	case ams.TxnSpecAxfer:
+		fs.Uint64Var(&s.Asset, "asset", 0, "asset id")
+		fs.StringVar(&s.Receiver, "receiver", "", "receiver address")
+		fs.Uint64Var(&s.Amount, "amount", 0, "amount in asset base units")
+		fs.StringVar(&s.CloseTo, "close-to", "", "close the sender asset holding to the address")
+	case ams.TxnSpecOptIn:
+		fs.Uint64Var(&s.Asset, "asset", 0, "asset id")
+	case ams.TxnSpecOptOut:
+		fs.Uint64Var(&s.Asset, "asset", 0, "asset id")
+		fs.StringVar(&s.CloseTo, "close-to", "", "receiver of the remaining asset balance (default - asset creator)")
+	case ams.TxnSpecAcfg:
+		fs.Uint64Var(&s.Asset, "asset", 0, "asset id to reconfigure or destroy (0 - create a new asset)")
+		fs.BoolVar(&s.Destroy, "destroy", false, "destroy the asset")
+		fs.Uint64Var(&s.Total, "total", 0, "total base units of the new asset")
+		fs.Func("decimals", "decimals of the new asset", func(v string) error {
+			d, err := strconv.ParseUint(v, 10, 32)
+			s.Decimals = uint32(d)
+			return err
+		})
+		fs.BoolVar(&s.DefaultFrozen, "default-frozen", false, "holdings of the new asset are frozen by default")
+		fs.StringVar(&s.UnitName, "unit-name", "", "unit name of the new asset")
+		fs.StringVar(&s.AssetName, "asset-name", "", "name of the new asset")
+		fs.StringVar(&s.URL, "url", "", "url of the new asset")
+		fs.Func("metadata-hash", "base64 encoded 32 byte metadata hash of the new asset", func(v string) error {
+			bs, err := base64.StdEncoding.DecodeString(v)
+			s.MetadataHash = bs
+			return err
+		})
+		fs.StringVar(&a.Manager, "manager", "", "manager address (new asset - sender if not set, reconfiguration - current if not set, empty to clear)")
+		fs.StringVar(&a.Reserve, "reserve", "", "reserve address (new asset - sender if not set, reconfiguration - current if not set, empty to clear)")
+		fs.StringVar(&a.Freeze, "freeze", "", "freeze address (new asset - sender if not set, reconfiguration - current if not set, empty to clear)")
+		fs.StringVar(&a.Clawback, "clawback", "", "clawback address (new asset - sender if not set, reconfiguration - current if not set, empty to clear)")
+	case ams.TxnSpecAfrz:
+		fs.Uint64Var(&s.Asset, "asset", 0, "asset id")
+		fs.StringVar(&s.FreezeTarget, "target", "", "account to freeze or unfreeze")
+		fs.BoolVar(&s.Frozen, "frozen", true, "new frozen state of the target holding")
+	case ams.TxnSpecKeyreg:
+		fs.StringVar(&s.VoteKey, "vote-key", "", "base64 participation vote key (none - go offline)")
+		fs.StringVar(&s.SelectionKey, "selection-key", "", "base64 participation selection key")
+		fs.StringVar(&s.StateProofKey, "state-proof-key", "", "base64 participation state proof key")
+		fs.Uint64Var(&s.VoteFirst, "vote-first", 0, "first participation round")
+		fs.Uint64Var(&s.VoteLast, "vote-last", 0, "last participation round")
+		fs.Uint64Var(&s.KeyDilution, "key-dilution", 0, "participation key dilution")
+		fs.BoolVar(&s.Nonparticipation, "nonparticipation", false, "mark the account as never participating")
+	case ams.TxnSpecAppl:
+		fs.Uint64Var(&s.App, "app", 0, "application id (0 - create a new app)")
+		fs.StringVar(&s.OnComplete, "on-complete", "noop", "on-completion: noop, optin, closeout, clear, update, delete")
+		fs.Var(&a.Args, "arg", "application arg: str:text, int:123, addr:ADDRESS, b64:data or hex:data")
+		fs.Var(&a.Accounts, "account", "foreign account address")
+		fs.Var(&a.ForeignApps, "foreign-app", "foreign application id")
+		fs.Var(&a.ForeignAssets, "foreign-asset", "foreign asset id")
+		fs.StringVar(&a.Approval, "approval", "", "compiled approval program file")
+		fs.StringVar(&a.Clear, "clear", "", "compiled clear state program file")
+		fs.Uint64Var(&s.GlobalInts, "global-ints", 0, "global state uints of the new app")
+		fs.Uint64Var(&s.GlobalBytes, "global-bytes", 0, "global state byte slices of the new app")
+		fs.Uint64Var(&s.LocalInts, "local-ints", 0, "local state uints of the new app")
+		fs.Uint64Var(&s.LocalBytes, "local-bytes", 0, "local state byte slices of the new app")
+		fs.Func("extra-pages", "extra program pages of the new app", func(v string) error {
+			p, err := strconv.ParseUint(v, 10, 32)
+			s.ExtraPages = uint32(p)
+			return err
+		})
+	}
+}
+
+// applyTxnArgs copies the flags that need parsing into the spec
+func applyTxnArgs(fs *flag.FlagSet, s *ams.TxnSpec, a txnArgs) error {
+	if len(a.Lease) > 0 {
+		bs, err := base64.StdEncoding.DecodeString(a.Lease)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode lease")
+		}
+
+		s.Lease = bs
+	}
+
+	for _, p := range []struct {
+		path string
+		dst  *[]byte
+	}{{a.Approval, &s.ApprovalProgram}, {a.Clear, &s.ClearProgram}} {
+		if len(p.path) == 0 {
+			continue
+		}
+
+		bs, err := os.ReadFile(p.path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read program file")
+		}
+
+		*p.dst = bs
+	}
+
+	s.Args = a.Args
+	s.Accounts = a.Accounts
+	s.ForeignApps = a.ForeignApps
+	s.ForeignAssets = a.ForeignAssets
+
+	// only the roles given explicitly are set so that the others can be kept
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "manager":
+			s.Manager = &a.Manager
+		case "reserve":
+			s.Reserve = &a.Reserve
+		case "freeze":
+			s.Freeze = &a.Freeze
+		case "clawback":
+			s.Clawback = &a.Clawback
+		}
+	})
+
+	return nil
+}
+
+// completeTxnSpec fills the opt-out close-to address and the asset roles that are kept from algod
+func completeTxnSpec(ac *algod.Client, s *ams.TxnSpec) error {
+	optOut := s.Type == ams.TxnSpecOptOut && len(s.CloseTo) == 0
+	reconfig := s.Type == ams.TxnSpecAcfg && s.Asset != 0 && !s.Destroy &&
+		(s.Manager == nil || s.Reserve == nil || s.Freeze == nil || s.Clawback == nil)
+
+	if !optOut && !reconfig {
+		return nil
+	}
+
+	asset, err := ac.GetAssetByID(s.Asset).Do(context.Background())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get asset: %d", s.Asset)
+	}
+
+	p := asset.Params
+
+	if optOut {
+		s.CloseTo = p.Creator
+		return nil
+	}
+
+	for _, r := range []struct {
+		dst     **string
+		current string
+	}{{&s.Manager, p.Manager}, {&s.Reserve, p.Reserve}, {&s.Freeze, p.Freeze}, {&s.Clawback, p.Clawback}} {
+		if *r.dst == nil {
+			current := r.current
+			*r.dst = &current
+		}
+	}
+
+	return nil
+}
+
+func runTxn(argv []string) error {
+	if len(argv) == 0 {
+		return errors.Errorf("missing txn type: %s", txnTypesUsage())
+	}
+
+	typ := argv[0]
+
+	var a txnArgs
+	var spec ams.TxnSpec
+
+	fs := flag.NewFlagSet("txn "+typ, flag.ExitOnError)
+	fs.StringVar(&a.Algod, "algod", "https://mainnet-api.algonode.cloud", "algod node address")
+	fs.StringVar(&a.AlgodToken, "algod-token", "", "algod node token")
+	fs.StringVar(&a.Out, "out", "", "unsigned transactions output file")
+
+	if typ != "spec" {
+		known := false
+		for _, t := range ams.TxnSpecTypes {
+			if string(t) == typ {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return errors.Errorf("unknown txn type: %s, expected: %s", typ, txnTypesUsage())
+		}
+
+		spec.Type = ams.TxnSpecType(typ)
+		addTxnFlags(fs, spec.Type, &spec, &a)
+	}
+
+	fs.Parse(argv[1:])
+
+	if len(a.Out) == 0 {
+		return errors.New("missing output file")
+	}
+
+	var specs []ams.TxnSpec
+
+	if typ == "spec" {
+		paths := fs.Args()
+		if len(paths) == 0 {
+			return errors.New("missing transaction spec files")
+		}
+
+		for _, p := range paths {
+			ss, err := ams.ReadTxnSpecFile(p)
+			if err != nil {
+				return errors.Wrapf(err, "failed to read spec: %s", p)
+			}
+
+			specs = append(specs, ss...)
+		}
+	} else {
+		err := applyTxnArgs(fs, &spec, a)
+		if err != nil {
+			return err
+		}
+
+		specs = append(specs, spec)
+	}
+
+	ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
+	if err != nil {
+		return errors.Wrap(err, "failed to make algod client")
+	}
+
+	sp, err := ac.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to get suggested params")
+	}
+
+	stxs := make([]types.SignedTxn, len(specs))
+
+	for i := range specs {
+		err = completeTxnSpec(ac, &specs[i])
+		if err != nil {
+			return err
+		}
+
+		stxs[i].Txn, err = ams.BuildTxn(specs[i], sp)
+		if err != nil {
+			return errors.Wrapf(err, "failed to build transaction #%d", i)
+		}
+	}
+
+	changes, err := ams.AssignGroup(stxs)
+	if err != nil {
+		return errors.Wrap(err, "failed to assign group id")
+	}
+
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+
+	fmt.Print(ams.DecodeTxnGroup(stxs).Text())
+
+	err = os.WriteFile(a.Out, ams.EncodeSignedTxns(stxs), 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write transactions")
+	}
+
+	fmt.Println("Written unsigned transactions:", a.Out)
+
+	return nil
+}
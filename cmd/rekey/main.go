@@ -1,73 +1,383 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/algorand/go-algorand-sdk/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/crypto"
-	"github.com/algorand/go-algorand-sdk/mnemonic"
-	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/ams"
+	"github.com/dragmz/tqr"
+	"github.com/dragmz/wc"
 	"github.com/pkg/errors"
 )
 
 type args struct {
-	Mnemonic string
+	Mnemonic       string
+	PrivateKeyPath string
+	WalletConnect  bool
 
 	Algod      string
 	AlgodToken string
 
-	RekeyTo string
+	Addr      string
+	Threshold int
+	AuthMsig  string
+
+	RekeyTo   string
+	RekeyBack bool
+
+	Fee  uint64
+	Note string
+	Out  string
+	Wait uint64
+
+	AddressBook string
+
+	PairTimeout time.Duration
+	PairTries   int
+	Debug       bool
+}
+
+func formatAuth(book *ams.AddressBook, auth string) string {
+	if len(auth) == 0 {
+		return "self"
+	}
+
+	return book.Format(auth)
+}
+
+// authAccount returns the current auth address of the account and the multisig account authorizing it, if any
+func authAccount(a args, as *ams.AddressSource, auth string) (*crypto.MultisigAccount, error) {
+	if len(auth) == 0 {
+		if len(a.AuthMsig) > 0 {
+			return nil, errors.New("the account is not rekeyed, -auth-msig is not needed")
+		}
+
+		return as.Multisig(), nil
+	}
+
+	if len(a.AuthMsig) == 0 {
+		return nil, nil
+	}
+
+	ma, err := ams.ParseMultisig(a.AuthMsig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse auth multisig")
+	}
+
+	maddr, err := ma.Address()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get auth multisig address")
+	}
+
+	if maddr.String() != auth {
+		return nil, errors.Errorf("auth multisig address mismatch - expected: %s, got: %s", auth, maddr)
+	}
+
+	return ma, nil
+}
+
+// readLocalAccount reads the key from the mnemonic or the private key file
+func readLocalAccount(a args) (*crypto.Account, error) {
+	accs, err := ams.MakeAccountSource(
+		ams.WithAccountSourceMnemonic(strings.ReplaceAll(a.Mnemonic, ",", " ")),
+		ams.WithAccountSourcePrivateKeyPath(a.PrivateKeyPath),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make account source")
+	}
+
+	acc, err := accs.ReadAccount()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read account from source")
+	}
+
+	return acc, nil
+}
+
+// makeLocalSigner checks that the local key can authorize the account
+func makeLocalSigner(acc *crypto.Account, addr string, signer string, ma *crypto.MultisigAccount) (ams.Signer, error) {
+	if ma != nil {
+		member := false
+		for _, pk := range ma.Pks {
+			if bytes.Equal(pk, acc.Address[:]) {
+				member = true
+				break
+			}
+		}
+
+		if !member {
+			return nil, errors.Errorf("the key of %s is not a member of the auth multisig %s", acc.Address, signer)
+		}
+	} else if acc.Address.String() != signer {
+		return nil, errors.Errorf("the key of %s cannot authorize the account, auth address: %s", acc.Address, signer)
+	}
+
+	return ams.MakeLocalSigner(addr, acc.PrivateKey,
+		ams.WithLocalSignerMultisigAccount(ma),
+	)
+}
+
+// makeProxySigner pairs the WalletConnect wallets holding the auth address or the auth multisig members
+func makeProxySigner(a args, book *ams.AddressBook, addr string, signer string, ma *crypto.MultisigAccount) (ams.Signer, error) {
+	popts := []ams.PairerOption{
+		ams.WithPairerTimeout(a.PairTimeout),
+		ams.WithPairerMaxTries(a.PairTries),
+		ams.WithPairerPeerMeta(wc.SessionRequestPeerMeta{Name: "AMS"}),
+		ams.WithPairerAddressBook(book),
+		ams.WithPairerDebug(a.Debug),
+		ams.WithPairerUrlHandler(func(uri wc.Uri) error {
+			s := uri.String()
+
+			fmt.Println(tqr.New(s))
+			fmt.Println(s)
+
+			return nil
+		}),
+	}
+
+	if ma != nil {
+		popts = append(popts, ams.WithPairerMultisigs([]crypto.MultisigAccount{*ma}))
+	} else {
+		member, err := types.DecodeAddress(signer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode auth address")
+		}
+
+		popts = append(popts,
+			ams.WithPairerMembers([]types.Address{member}),
+			ams.WithPairerThreshold(1),
+		)
+	}
+
+	pr, err := ams.MakePairer(popts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make pairer")
+	}
+
+	pa, err := pr.Pair()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to pair signers")
+	}
+
+	proxyAddr := signer
+	if ma != nil {
+		proxyAddr = addr
+	}
+
+	return ams.MakeProxySigner(proxyAddr,
+		ams.WithProxySignerDebug(a.Debug),
+		ams.WithProxySignerMultisig(ma),
+		ams.WithProxySignerPeersCallback(func() []ams.PeerAddr {
+			return pa
+		}),
+	)
 }
 
 func run(a args) error {
+	if a.RekeyBack == (len(a.RekeyTo) > 0) {
+		return errors.New("either -rekey-to or -rekey-back is required")
+	}
+
+	local := len(a.Mnemonic) > 0 || len(a.PrivateKeyPath) > 0
+	if local && a.WalletConnect {
+		return errors.New("-wc cannot be used with -mnemonic or -pk-path")
+	}
+
+	if !local && !a.WalletConnect && len(a.Out) == 0 {
+		return errors.New("missing signer: -mnemonic, -pk-path, -wc or -out for an unsigned transaction file")
+	}
+
+	var acc *crypto.Account
+
+	if local {
+		var err error
+		acc, err = readLocalAccount(a)
+		if err != nil {
+			return err
+		}
+
+		// the key's own account is rekeyed unless another one is given
+		if len(a.Addr) == 0 {
+			a.Addr = acc.Address.String()
+		}
+	}
+
+	book, err := ams.MakeAddressBook(ams.WithAddressBookFile(a.AddressBook))
+	if err != nil {
+		return errors.Wrap(err, "failed to load address book")
+	}
+
+	as, err := ams.MakeAddressSource(
+		ams.WithAddressString(a.Addr),
+		ams.WithAddressThreshold(a.Threshold),
+		ams.WithAddressBook(book),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to make address source")
+	}
+
+	addr := as.Address()
+
 	ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
 	if err != nil {
 		return errors.Wrap(err, "failed to make algod client")
 	}
 
-	a.Mnemonic = strings.ReplaceAll(a.Mnemonic, ",", " ")
-
-	sk, err := mnemonic.ToPrivateKey(a.Mnemonic)
+	info, err := ac.AccountInformation(addr).Do(context.Background())
 	if err != nil {
-		return errors.Wrap(err, "failed to convert mnemonic to private key")
+		return errors.Wrap(err, "failed to get account information")
+	}
+
+	target := a.RekeyTo
+	if a.RekeyBack {
+		target = addr
 	}
 
-	acc, err := crypto.AccountFromPrivateKey(sk)
+	if target == info.AuthAddr || (target == addr && len(info.AuthAddr) == 0) {
+		return errors.Errorf("the account is already controlled by %s", formatAuth(book, info.AuthAddr))
+	}
+
+	newAuth := target
+	if target == addr {
+		newAuth = ""
+	}
+
+	signer := addr
+	if len(info.AuthAddr) > 0 {
+		signer = info.AuthAddr
+	}
+
+	ma, err := authAccount(a, as, info.AuthAddr)
 	if err != nil {
-		return errors.Wrap(err, "failed to convert private key to account")
+		return err
 	}
 
-	fmt.Println("Rekeyed address:", acc.Address)
+	if local && ma != nil && ma.Threshold > 1 && len(a.Out) == 0 {
+		return errors.Errorf("the auth multisig needs %d signatures - use -wc or write the partially signed transaction with -out", ma.Threshold)
+	}
 
 	sp, err := ac.SuggestedParams().Do(context.Background())
 	if err != nil {
-		return errors.Wrap(err, "failed to get status")
+		return errors.Wrap(err, "failed to get suggested params")
+	}
+
+	txn, err := ams.BuildTxn(ams.TxnSpec{
+		Type:     ams.TxnSpecPay,
+		Sender:   addr,
+		Receiver: addr,
+		Fee:      a.Fee,
+		Note:     a.Note,
+		RekeyTo:  target,
+	}, sp)
+	if err != nil {
+		return errors.Wrap(err, "failed to make rekey transaction")
 	}
 
-	tx, err := transaction.MakePaymentTxnWithFlatFee(acc.Address.String(), acc.Address.String(), transaction.MinTxnFee, 0, uint64(sp.FirstRoundValid), uint64(sp.LastRoundValid), nil, "", sp.GenesisID, sp.GenesisHash)
+	stxs := []types.SignedTxn{{Txn: txn}}
+
+	risk, err := ams.MakeRiskAnalyzer(
+		ams.WithRiskOwnAddresses([]string{addr}),
+		ams.WithRiskAddressBook(book),
+	)
 	if err != nil {
-		return errors.Wrap(err, "failed to make tx")
+		return errors.Wrap(err, "failed to make risk analyzer")
+	}
+
+	g := ams.DecodeTxnGroup(stxs,
+		ams.WithFormatAddressBook(book),
+		ams.WithFormatRiskAnalyzer(risk),
+	)
+
+	fmt.Print(g.Text())
+	fmt.Println("Account:", book.Format(addr))
+	fmt.Println("Auth address before:", formatAuth(book, info.AuthAddr))
+	fmt.Println("Auth address after:", formatAuth(book, newAuth))
+
+	if !local && !a.WalletConnect {
+		err = os.WriteFile(a.Out, ams.EncodeSignedTxns(stxs), 0644)
+		if err != nil {
+			return errors.Wrap(err, "failed to write unsigned transaction")
+		}
+
+		fmt.Println("Written unsigned transaction:", a.Out)
+
+		return nil
 	}
 
-	err = tx.Rekey(a.RekeyTo)
+	ok, err := ams.ConfirmFindings(bufio.NewReader(os.Stdin), g.Findings(), "rekey the account")
 	if err != nil {
-		return errors.Wrap(err, "failed to set rekey to")
+		return err
+	}
+
+	if !ok {
+		return errors.New("rekey not confirmed")
 	}
 
-	_, stx, err := crypto.SignTransaction(sk, tx)
+	var s ams.Signer
+
+	if local {
+		s, err = makeLocalSigner(acc, addr, signer, ma)
+	} else {
+		s, err = makeProxySigner(a, book, addr, signer, ma)
+	}
 	if err != nil {
-		return errors.Wrap(err, "failed to sign tx")
+		return errors.Wrap(err, "failed to make signer")
 	}
 
-	txid, err := ac.SendRawTransaction(stx).Do(context.Background())
+	if len(a.Out) > 0 {
+		signed, err := ams.SignMissing(s, stxs)
+		if err != nil {
+			return errors.Wrap(err, "failed to sign transaction")
+		}
+
+		err = os.WriteFile(a.Out, bytes.Join(signed, nil), 0644)
+		if err != nil {
+			return errors.Wrap(err, "failed to write signed transaction")
+		}
+
+		fmt.Println("Written signed transaction:", a.Out)
+
+		return nil
+	}
+
+	var r ams.Runner
+	r, err = ams.MakeSendRunner(stxs,
+		ams.WithSendRunnerSigner(s),
+		ams.WithSendRunnerAlgod(ac),
+		ams.WithSendRunnerWaitRounds(a.Wait),
+	)
 	if err != nil {
-		return errors.Wrap(err, "failed to send tx")
+		return errors.Wrap(err, "failed to make send runner")
 	}
 
-	fmt.Println("Transaction ID:", txid)
+	err = r.Run()
+	if err != nil {
+		return errors.Wrap(err, "failed to send rekey transaction")
+	}
+
+	if a.Wait == 0 {
+		return nil
+	}
+
+	info, err = ac.AccountInformation(addr).Do(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to get account information")
+	}
+
+	fmt.Println("Auth address from algod:", formatAuth(book, info.AuthAddr))
+
+	if info.AuthAddr != newAuth {
+		return errors.Errorf("unexpected auth address - expected: %s, got: %s", formatAuth(book, newAuth), formatAuth(book, info.AuthAddr))
+	}
 
 	return nil
 }
@@ -77,8 +387,22 @@ func main() {
 
 	flag.StringVar(&a.Algod, "algod", "https://mainnet-api.algonode.cloud", "algod address")
 	flag.StringVar(&a.AlgodToken, "algod-token", "", "algod token")
-	flag.StringVar(&a.Mnemonic, "mnemonic", "", "mnemonic")
+	flag.StringVar(&a.Mnemonic, "mnemonic", "", "mnemonic of the key authorizing the account")
+	flag.StringVar(&a.PrivateKeyPath, "pk-path", "", "encrypted private key json file of the key authorizing the account")
+	flag.BoolVar(&a.WalletConnect, "wc", false, "sign with WalletConnect wallets holding the auth address or the auth multisig members")
+	flag.StringVar(&a.Addr, "addr", "", "rekeyed account address or comma separated multisig member addresses, defaults to the local key's address")
+	flag.IntVar(&a.Threshold, "threshold", 1, "multisig threshold of the rekeyed account")
+	flag.StringVar(&a.AuthMsig, "auth-msig", "", "multisig the account is currently rekeyed to: threshold:addr1,addr2,..")
 	flag.StringVar(&a.RekeyTo, "rekey-to", "", "rekey-to address")
+	flag.BoolVar(&a.RekeyBack, "rekey-back", false, "rekey the account back to its own key")
+	flag.Uint64Var(&a.Fee, "fee", 0, "flat fee in microALGO (0 - suggested fee)")
+	flag.StringVar(&a.Note, "note", "", "transaction note")
+	flag.StringVar(&a.Out, "out", "", "write the signed transaction to a file instead of sending it, or the unsigned one if no signer is given")
+	flag.Uint64Var(&a.Wait, "wait", ams.DefaultWaitRounds, "rounds to wait for the confirmation and the new auth address check (0 - no waiting)")
+	flag.StringVar(&a.AddressBook, "address-book", "", "address book json file with address labels")
+	flag.DurationVar(&a.PairTimeout, "pair-timeout", 0, "signers pairing timeout (0 - no timeout)")
+	flag.IntVar(&a.PairTries, "pair-tries", 0, "max signers pairing attempts (0 - unlimited)")
+	flag.BoolVar(&a.Debug, "debug", false, "debug mode")
 	flag.Parse()
 
 	err := run(a)
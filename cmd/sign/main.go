@@ -6,8 +6,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
-	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/crypto"
 	"github.com/algorand/go-algorand-sdk/types"
 	"github.com/dragmz/ams"
 	"github.com/dragmz/wc"
@@ -18,7 +20,10 @@ type args struct {
 	Mnemonic    string
 	Addr        string
 	Threshold   int
+	MsigFile    string
 	Txn         string
+	In          string
+	Out         string
 	Debug       bool
 	Uri         string
 	AuthAddr    string
@@ -26,38 +31,105 @@ type args struct {
 
 	ClipboardUri bool
 
+	Algod      string
+	AlgodToken string
+	Simulate   bool
+
+	ResolveAssets bool
+	AssetCache    string
+	AbiDir        string
+	Output        string
+	AddressBook   string
+	Validity      bool
+
+	Own         string
+	Known       string
+	MaxFee      uint64
+	MaxValidity uint64
+
 	PrivateKeyPath string
 }
 
 type manualConfirmSignerWrapper struct {
-	s wc.Signer
-	r *bufio.Reader
+	s    wc.Signer
+	r    *bufio.Reader
+	sim  *ams.Simulator
+	risk *ams.RiskAnalyzer
+
+	fopts  []ams.FormatOption
+	output ams.OutputFormat
+
+	// ac is used to place the validity windows in wall-clock time
+	ac *algod.Client
+
+	// ma is the multisig account the transactions are authorized by
+	ma *crypto.MultisigAccount
+}
+
+// confirm shows the risk findings and asks to sign, with the confirmation phrase if any of them is critical
+func (s *manualConfirmSignerWrapper) confirm(findings []ams.RiskFinding) (bool, error) {
+	if len(findings) > 0 {
+		fmt.Println("Risk findings:")
+		for _, f := range findings {
+			fmt.Println(f)
+		}
+	}
+
+	return ams.ConfirmFindings(s.r, findings, "sign transactions")
 }
 
 func (s *manualConfirmSignerWrapper) Sign(req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error) {
 	fmt.Println("Incoming transactions:")
 
-	if len(req.Params) > 0 {
-		p := req.Params[0]
-		for _, item := range p {
-			bs, err := base64.StdEncoding.DecodeString(item.TxnBase64)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to base64 decode transaction")
-			}
+	stxs, err := ams.SignRequestSimulateTxns(req, s.ma)
+	if err != nil {
+		return nil, err
+	}
+
+	fopts := append(append([]ams.FormatOption{}, s.fopts...), ams.WithFormatRiskAnalyzer(s.risk))
+
+	if s.ac != nil {
+		clock, err := ams.FetchRoundClock(s.ac, ams.DefaultBlockTimeSamples)
+		if err != nil {
+			fmt.Println("Validity estimate error:", err)
+		} else {
+			fopts = append(fopts, ams.WithFormatRoundClock(clock))
+		}
+	}
+
+	g := ams.DecodeTxnGroup(stxs, fopts...)
 
-			var txn types.Transaction
-			err = msgpack.Decode(bs, &txn)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to decode transaction msgpack")
-			}
+	if s.output == ams.OutputText {
+		for _, d := range g.Txns {
+			fmt.Println(d.Text())
+		}
+	} else {
+		text, err := g.Render(s.output)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Print(text)
+	}
 
-			fmt.Println(ams.FormatTxn(txn))
+	if s.sim != nil && len(stxs) > 0 {
+		res, err := s.sim.Simulate(stxs)
+		if err != nil {
+			fmt.Println("Simulation error:", err)
+		} else {
+			res.Print()
 		}
 	}
 
-	// TODO: decode and display transactions
-	fmt.Println("Press Enter to sign transactions..")
-	s.r.ReadString('\n')
+	ok, err := s.confirm(g.Findings())
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		fmt.Println("Rejected transactions.")
+		return ams.MakeRejectedSignResponse("Rejected by the user"), nil
+	}
 
 	resp, err := s.s.Sign(req)
 	if err != nil {
@@ -73,27 +145,121 @@ func (s *manualConfirmSignerWrapper) Address() string {
 	return s.s.Address()
 }
 
-func run(a args) error {
-	as, err := ams.MakeAddressSource(
-		ams.WithAddressString(a.Addr),
-		ams.WithAddressThreshold(a.Threshold),
-	)
+func readOfflineTxns(a args, rdr *bufio.Reader) ([]types.SignedTxn, error) {
+	if len(a.Txn) > 0 && len(a.In) > 0 {
+		return nil, errors.New("cannot read transactions from multiple sources")
+	}
+
+	var txs []ams.InputTxn
+	var err error
+
+	text := a.Txn
+
+	if len(a.In) > 0 {
+		txs, err = ams.ReadTxnFile(a.In)
+		if err != nil {
+			return nil, err
+		}
+
+		return ams.InputSignedTxns(txs), nil
+	}
+
+	if text == "-" {
+		fmt.Println("Enter transactions data or <file:")
+
+		input, err := ams.ReadInput(rdr)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read transactions input")
+		}
+
+		text = input
+	} else if strings.HasPrefix(text, "<") {
+		bs, err := os.ReadFile(text[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read transactions file")
+		}
+
+		text = string(bs)
+	}
+
+	txs, err = ams.DecodeTxns([]byte(text), "input")
 	if err != nil {
-		return errors.Wrap(err, "failed to make address source")
+		return nil, err
 	}
 
-	us, err := ams.MakeUriSource(
-		ams.WithUriSourceStaticUri(a.Uri),
-		ams.WithUriSourceClipboardUri(a.ClipboardUri),
-		ams.WithUriSourceNonEmpty(true),
+	return ams.InputSignedTxns(txs), nil
+}
+
+func runOffline(a args, signer ams.Signer, rdr *bufio.Reader) error {
+	stxs, err := readOfflineTxns(a, rdr)
+	if err != nil {
+		return errors.Wrap(err, "failed to read transactions")
+	}
+
+	res, err := ams.SignMissing(signer, stxs)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign transactions")
+	}
+
+	var out []byte
+	for _, bs := range res {
+		out = append(out, bs...)
+	}
+
+	if len(a.Out) > 0 {
+		err = os.WriteFile(a.Out, out, 0644)
+		if err != nil {
+			return errors.Wrap(err, "failed to write signed transactions")
+		}
+
+		fmt.Println("Written signed transactions:", a.Out)
+	} else {
+		fmt.Println(base64.StdEncoding.EncodeToString(out))
+	}
+
+	return nil
+}
+
+func splitAddresses(s string) []string {
+	var res []string
+
+	for _, addr := range strings.Split(s, ",") {
+		addr = strings.TrimSpace(addr)
+		if len(addr) > 0 {
+			res = append(res, addr)
+		}
+	}
+
+	return res
+}
+
+func run(a args) error {
+	var desc *ams.MultisigDescriptor
+
+	if len(a.MsigFile) > 0 {
+		var err error
+		desc, err = ams.ReadMultisigDescriptorFile(a.MsigFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	book, err := ams.MakeAddressBook(
+		ams.WithAddressBookFile(a.AddressBook),
+		ams.WithAddressBookDescriptor(desc),
 	)
 	if err != nil {
-		return errors.Wrap(err, "failed to make uri source")
+		return errors.Wrap(err, "failed to load address book")
 	}
 
-	uri, err := us.Uri()
+	as, err := ams.MakeAddressSource(
+		ams.WithAddressString(a.Addr),
+		ams.WithAddressThreshold(a.Threshold),
+		ams.WithAddressDescriptor(desc),
+		ams.WithAddressBook(book),
+	)
 	if err != nil {
-		return errors.Wrap(err, "failed to read uri from source")
+		return errors.Wrap(err, "failed to make address source")
 	}
 
 	accs, err := ams.MakeAccountSource(
@@ -117,19 +283,118 @@ func run(a args) error {
 		return errors.Wrap(err, "failed to make signer")
 	}
 
+	if len(a.AuthAddr) > 0 && a.AuthAddr != signer.Address() {
+		return errors.Errorf("auth address mismatch - expected: %s, signer: %s", a.AuthAddr, signer.Address())
+	}
+
 	rdr := bufio.NewReader(os.Stdin)
 
-	signer = &manualConfirmSignerWrapper{
-		s: signer,
-		r: rdr,
+	var sim *ams.Simulator
+
+	if a.Simulate {
+		// the signatures of the other signers are not available yet
+		sim, err = ams.MakeSimulator(a.Algod, a.AlgodToken,
+			ams.WithSimulatorAllowEmptySignatures(true),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to make simulator")
+		}
+	}
+
+	fopts := []ams.FormatOption{
+		ams.WithFormatAddressBook(book),
+	}
+
+	if a.ResolveAssets {
+		ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
+		if err != nil {
+			return errors.Wrap(err, "failed to make algod client")
+		}
+
+		ar, err := ams.MakeAlgodAssetResolver(ac,
+			ams.WithAlgodAssetResolverCacheFile(a.AssetCache),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to make asset resolver")
+		}
+
+		fopts = append(fopts, ams.WithFormatAssetResolver(ar))
+	}
+
+	if len(a.AbiDir) > 0 {
+		reg, err := ams.MakeABIRegistry(ams.WithABIRegistryDir(a.AbiDir))
+		if err != nil {
+			return errors.Wrap(err, "failed to load abi specs")
+		}
+
+		fopts = append(fopts, ams.WithFormatABIRegistry(reg))
+	}
+
+	own := []string{signer.Address()}
+	if len(a.MatchSender) > 0 {
+		own = append(own, a.MatchSender)
+	}
+
+	risk, err := ams.MakeRiskAnalyzer(
+		ams.WithRiskOwnAddresses(append(own, splitAddresses(a.Own)...)),
+		ams.WithRiskKnownAddresses(splitAddresses(a.Known)),
+		ams.WithRiskAddressBook(book),
+		ams.WithRiskMaxFee(a.MaxFee),
+		ams.WithRiskMaxValidity(a.MaxValidity),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to make risk analyzer")
+	}
+
+	output, err := ams.ParseOutputFormat(a.Output)
+	if err != nil {
+		return err
+	}
+
+	w := &manualConfirmSignerWrapper{
+		s:      signer,
+		r:      rdr,
+		sim:    sim,
+		risk:   risk,
+		fopts:  fopts,
+		output: output,
+		ma:     as.Multisig(),
+	}
+
+	if a.Validity {
+		w.ac, err = algod.MakeClient(a.Algod, a.AlgodToken)
+		if err != nil {
+			return errors.Wrap(err, "failed to make algod client")
+		}
+	}
+
+	signer = w
+
+	if len(a.Txn) > 0 || len(a.In) > 0 {
+		return runOffline(a, signer, rdr)
+	}
+
+	us, err := ams.MakeUriSource(
+		ams.WithUriSourceStaticUri(a.Uri),
+		ams.WithUriSourceClipboardUri(a.ClipboardUri),
+		ams.WithUriSourceNonEmpty(true),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to make uri source")
+	}
+
+	uri, err := us.Uri()
+	if err != nil {
+		return errors.Wrap(err, "failed to read uri from source")
 	}
 
-	wallet, err := wc.MakeServer(*uri, signer,
-		wc.WithServerDebug(a.Debug),
+	wallet, err := ams.MakeServer(*uri, signer,
+		ams.WithServerDebug(a.Debug),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to make wallet")
 	}
+	defer wallet.Close()
 
 	err = wallet.Run()
 	if err != nil {
@@ -148,12 +413,28 @@ func main() {
 	flag.StringVar(&a.Addr, "addr", "", "multisig addresses")
 
 	flag.IntVar(&a.Threshold, "threshold", 0, "multisig threshold")
-	flag.StringVar(&a.Txn, "txn", "", "base32 transaction data")
+	flag.StringVar(&a.MsigFile, "msig-file", "", "multisig descriptor json file, instead of -addr and -threshold")
+	flag.StringVar(&a.Txn, "txn", "", "offline mode: base64 or base32 transactions data, <file or - to read from stdin")
+	flag.StringVar(&a.In, "in", "", "offline mode: msgpack transactions input file")
+	flag.StringVar(&a.Out, "out", "", "offline mode: signed transactions output file (base64 to stdout if not set)")
 	flag.BoolVar(&a.Debug, "debug", false, "debug mode")
 	flag.StringVar(&a.Uri, "uri", "", "WalletConnect uri")
-	flag.StringVar(&a.AuthAddr, "auth-addr", "", "Algorand auth address")
+	flag.StringVar(&a.AuthAddr, "auth-addr", "", "expected Algorand auth address of the signer")
 	flag.BoolVar(&a.ClipboardUri, "cu", false, "use WalletConnect uri from clipboard")
 	flag.StringVar(&a.MatchSender, "match", "", "sign only transactions with matching sender")
+	flag.StringVar(&a.Algod, "algod", "https://mainnet-api.algonode.cloud", "algod node address")
+	flag.StringVar(&a.AlgodToken, "algod-token", "", "algod node token")
+	flag.BoolVar(&a.ResolveAssets, "resolve-assets", false, "show asset amounts with decimals and unit names from algod")
+	flag.StringVar(&a.AbiDir, "abi-dir", "", "directory of ARC-4 contract or ARC-56 app spec json files used to decode app calls")
+	flag.StringVar(&a.AssetCache, "asset-cache", "", "asset metadata cache file")
+	flag.StringVar(&a.Output, "output", "text", "incoming transactions output format: text, json or yaml")
+	flag.BoolVar(&a.Validity, "validity", false, "show the validity windows in wall-clock time using the algod status")
+	flag.StringVar(&a.AddressBook, "address-book", "", "address book json file with address labels")
+	flag.StringVar(&a.Own, "own", "", "comma separated addresses of our accounts, in addition to the signer")
+	flag.StringVar(&a.Known, "known", "", "comma separated known receiver addresses; other receivers are flagged")
+	flag.Uint64Var(&a.MaxFee, "max-fee", ams.DefaultRiskMaxFee, "fee in microALGO above which a transaction is flagged")
+	flag.Uint64Var(&a.MaxValidity, "max-validity", ams.DefaultRiskMaxValidity, "validity window in rounds above which a transaction is flagged")
+	flag.BoolVar(&a.Simulate, "simulate", false, "simulate the transactions before confirming them")
 
 	flag.Parse()
 
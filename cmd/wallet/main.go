@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/algorand/go-algorand-sdk/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/crypto"
@@ -18,15 +19,42 @@ type args struct {
 	Algod      string
 	AlgodToken string
 
-	Paths pathsArg
+	Paths   listArg
+	Inbox   string
+	Poll    time.Duration
+	Out     string
+	OutMode string
+	OutName string
+	Wait    uint64
+	Json    bool
+
+	Simulate         bool
+	SimulateUnsigned bool
+
+	Group    bool
+	PoolFees bool
+	Refresh  bool
 
 	Uri          string
 	ClipboardUri bool
 
 	Address   string
 	Threshold uint
+	Multisigs listArg
+	MsigFiles listArg
+
+	PairTimeout time.Duration
+	PairTries   int
+
+	AddressBook string
+
+	Validity bool
+	SigTime  time.Duration
+
+	Dashboard bool
 
-	Debug bool
+	Debug       bool
+	DebugFormat string
 }
 
 func run(a args) error {
@@ -34,6 +62,27 @@ func run(a args) error {
 		return errors.New("threshold must be >= 0")
 	}
 
+	var descs []*ams.MultisigDescriptor
+
+	bopts := []ams.AddressBookOption{
+		ams.WithAddressBookFile(a.AddressBook),
+	}
+
+	for _, p := range a.MsigFiles {
+		d, err := ams.ReadMultisigDescriptorFile(p)
+		if err != nil {
+			return err
+		}
+
+		descs = append(descs, d)
+		bopts = append(bopts, ams.WithAddressBookDescriptor(d))
+	}
+
+	book, err := ams.MakeAddressBook(bopts...)
+	if err != nil {
+		return errors.Wrap(err, "failed to load address book")
+	}
+
 	accs, err := ams.ParseAddrs(a.Address, ",")
 	if err != nil {
 		return err
@@ -51,6 +100,11 @@ func run(a args) error {
 	case 1:
 		addr = accs[0].String()
 	default:
+		err = ams.ValidateMultisig(int(a.Threshold), accs)
+		if err != nil {
+			return err
+		}
+
 		mma, err := crypto.MultisigAccountWithParams(1, uint8(a.Threshold), accs)
 		if err != nil {
 			return err
@@ -63,7 +117,44 @@ func run(a args) error {
 		addr = ad.String()
 		ma = &mma
 
-		fmt.Println("Multisig:", addr)
+		fmt.Println("Multisig:", book.Format(addr))
+	}
+
+	var mas []crypto.MultisigAccount
+
+	for _, def := range a.Multisigs {
+		mma, err := ams.ParseMultisig(def)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse multisig: %s", def)
+		}
+
+		ad, err := mma.Address()
+		if err != nil {
+			return err
+		}
+
+		if len(addr) == 0 {
+			addr = ad.String()
+		}
+
+		mas = append(mas, *mma)
+
+		fmt.Println("Multisig:", book.Format(ad.String()))
+	}
+
+	for _, d := range descs {
+		mma, err := d.MultisigAccount()
+		if err != nil {
+			return err
+		}
+
+		if len(addr) == 0 {
+			addr = d.Address
+		}
+
+		mas = append(mas, *mma)
+
+		fmt.Println("Multisig:", book.Format(d.Address))
 	}
 
 	us, err := ams.MakeUriSource(
@@ -99,56 +190,53 @@ func run(a args) error {
 		}
 	}()
 
-	addrs_left := map[string]bool{}
-	for _, acc := range accs {
-		addrs_left[acc.String()] = true
+	pr, err := ams.MakePairer(
+		ams.WithPairerMembers(accs),
+		ams.WithPairerThreshold(int(a.Threshold)),
+		ams.WithPairerMultisigs(mas),
+		ams.WithPairerTimeout(a.PairTimeout),
+		ams.WithPairerMaxTries(a.PairTries),
+		ams.WithPairerPeerMeta(meta),
+		ams.WithPairerAddressBook(book),
+		ams.WithPairerDebug(a.Debug),
+		ams.WithPairerUrlHandler(func(uri wc.Uri) error {
+			uch <- uri
+			return nil
+		}),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to make pairer")
 	}
 
-	var pa []ams.PeerAddr
-	var tries uint
-
-	for len(pa) < int(a.Threshold) {
-		fmt.Printf("Signers - need: %d / %d, got: %d, tries: %d:\n", a.Threshold, len(accs), len(pa), tries)
-
-		peer, res, err := wc.MakeClient(
-			meta,
-			wc.WithClientDebug(a.Debug),
-			wc.WithClientUrlHandler(func(uri wc.Uri) error {
-				uch <- uri
-				return nil
-			}))
-		if err != nil {
-			return errors.Wrap(err, "failed to make client")
-		}
-
-		tries++
-
-		// TODO: supports first address only
-		for _, addr := range res.Accounts {
-			if addrs_left[addr] || len(accs) == 0 {
-				delete(addrs_left, addr)
-				pa = append(pa, ams.PeerAddr{
-					Peer:    peer,
-					Address: addr,
-				})
-				break
-			}
-		}
-
-		// TODO: disconnect if not accepted
+	pa, err := pr.Pair()
+	if err != nil {
+		return errors.Wrap(err, "failed to pair signers")
 	}
 
 	if len(addr) == 0 {
 		addr = pa[0].Address
 	}
 
-	s, err := ams.MakeProxySigner(addr,
+	ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
+	if err != nil {
+		return errors.Wrap(err, "failed to make algod client")
+	}
+
+	popts := []ams.ProxySignerOption{
 		ams.WithProxySignerDebug(a.Debug),
 		ams.WithProxySignerMultisig(ma),
+		ams.WithProxySignerMultisigs(mas),
 		ams.WithProxySignerPeersCallback(func() []ams.PeerAddr {
 			return pa
 		}),
-	)
+		ams.WithProxySignerSignatureTime(a.SigTime),
+	}
+
+	if a.Validity {
+		popts = append(popts, ams.WithProxySignerAlgod(ac))
+	}
+
+	s, err := ams.MakeProxySigner(addr, popts...)
 	if err != nil {
 		return errors.Wrap(err, "failed to create proxy signer")
 	}
@@ -156,26 +244,47 @@ func run(a args) error {
 	var runners []ams.Runner
 
 	if u != nil {
-		w, err := wc.MakeServer(*u, s,
-			wc.WithServerDebug(a.Debug),
+		w, err := ams.MakeServer(*u, s,
+			ams.WithServerDebug(a.Debug),
 		)
 		if err != nil {
 			return errors.Wrap(err, "failed to make server")
 		}
+		defer w.Close()
 
 		runners = append(runners, w)
 	}
 
-	if len(a.Paths) > 0 {
-		ac, err := algod.MakeClient(a.Algod, a.AlgodToken)
-		if err != nil {
-			return errors.Wrap(err, "failed to make algod client")
+	if len(a.Paths) > 0 || len(a.Inbox) > 0 {
+		var sim *ams.Simulator
+
+		if a.Simulate || a.SimulateUnsigned {
+			sim, err = ams.MakeSimulator(a.Algod, a.AlgodToken,
+				ams.WithSimulatorAllowEmptySignatures(a.SimulateUnsigned),
+			)
+			if err != nil {
+				return errors.Wrap(err, "failed to make simulator")
+			}
 		}
 
 		r, err := ams.MakeFsRunner(a.Paths,
 			ams.WithFsRunnerDebug(a.Debug),
+			ams.WithFsRunnerDebugFormat(ams.OutputFormat(a.DebugFormat)),
 			ams.WithFsRunnerAlgod(ac),
 			ams.WithFsRunnerSigner(s),
+			ams.WithFsRunnerOutput(a.Out),
+			ams.WithFsRunnerOutputMode(ams.FsOutputMode(a.OutMode)),
+			ams.WithFsRunnerOutputName(a.OutName),
+			ams.WithFsRunnerWaitRounds(a.Wait),
+			ams.WithFsRunnerJson(a.Json),
+			ams.WithFsRunnerSimulator(sim),
+			ams.WithFsRunnerInbox(a.Inbox),
+			ams.WithFsRunnerAssignGroup(a.Group),
+			ams.WithFsRunnerPoolFees(a.PoolFees),
+			ams.WithFsRunnerRefresh(a.Refresh),
+			ams.WithFsRunnerPollInterval(a.Poll),
+			ams.WithFsRunnerMultisigDescriptorFiles(a.MsigFiles),
+			ams.WithFsRunnerAddressBook(book),
 		)
 		if err != nil {
 			return errors.Wrap(err, "failed to make paths source")
@@ -184,6 +293,40 @@ func run(a args) error {
 		runners = append(runners, r)
 	}
 
+	if a.Dashboard {
+		aopts := []ams.AddressSourceOption{
+			ams.WithAddressString(a.Address),
+			ams.WithAddressThreshold(int(a.Threshold)),
+			ams.WithAddressBook(book),
+		}
+
+		switch {
+		case len(accs) > 0:
+		case len(descs) > 0:
+			aopts = append(aopts, ams.WithAddressDescriptor(descs[0]))
+		default:
+			return errors.New("dashboard requires an address or a multisig descriptor")
+		}
+
+		as, err := ams.MakeAddressSource(aopts...)
+		if err != nil {
+			return errors.Wrap(err, "failed to make address source")
+		}
+
+		w, err := ams.MakeWallet(
+			ams.WithWalletAlgod(ac),
+			ams.WithWalletAddressSource(as),
+			ams.WithWalletSigner(s),
+			ams.WithWalletAddressBook(book),
+			ams.WithWalletWaitRounds(a.Wait),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to make wallet")
+		}
+
+		runners = append(runners, w)
+	}
+
 	var swg sync.WaitGroup
 	for _, r := range runners {
 		swg.Add(1)
@@ -210,18 +353,18 @@ func run(a args) error {
 	return nil
 }
 
-type pathsArg []string
+type listArg []string
 
-func (i *pathsArg) String() string {
+func (i *listArg) String() string {
 	return strings.Join(*i, ",")
 }
 
-func (i *pathsArg) Set(value string) error {
+func (i *listArg) Set(value string) error {
 	*i = append(*i, value)
 	return nil
 }
 
-var myFlags pathsArg
+var myFlags listArg
 
 func main() {
 	var a args
@@ -231,9 +374,30 @@ func main() {
 	flag.StringVar(&a.Uri, "uri", "", "WalletConnect uri")
 	flag.StringVar(&a.Address, "addr", "", "Algorand account address")
 	flag.UintVar(&a.Threshold, "threshold", 1, "Multisig threshold")
+	flag.StringVar(&a.AddressBook, "address-book", "", "address book json file with address labels")
+	flag.BoolVar(&a.Validity, "validity", false, "warn when the validity window closes before the signatures are likely to be collected")
+	flag.DurationVar(&a.SigTime, "sig-time", ams.DefaultProxySignatureTime, "expected time a cosigner needs to sign a request")
+	flag.BoolVar(&a.Dashboard, "dashboard", false, "show the account dashboard and send payments and asset transfers interactively")
 	flag.BoolVar(&a.Debug, "debug", false, "debug mode")
+	flag.StringVar(&a.DebugFormat, "debug-format", "text", "debug mode decoded transactions format: text, json or yaml")
 	flag.Var(&a.Paths, "path", "transactions input paths")
+	flag.StringVar(&a.Inbox, "inbox", "", "watch the directory and process every new transactions file as a separate group")
+	flag.DurationVar(&a.Poll, "poll", ams.DefaultFsPollInterval, "inbox polling interval")
+	flag.BoolVar(&a.Group, "group", false, "assign a group id to the input transactions")
+	flag.BoolVar(&a.PoolFees, "pool-fees", false, "move the group fees of each sender to its first transaction using the suggested params")
+	flag.BoolVar(&a.Refresh, "refresh", false, "re-stamp validity, genesis and fees of the unsigned input transactions")
+	flag.StringVar(&a.Out, "out", "", "write signed transactions to a file (group mode) or a directory (split mode, inbox outbox) instead of sending them")
+	flag.StringVar(&a.OutMode, "out-mode", string(ams.FsOutputGroup), "output mode: group - one concatenated file, split - one file per transaction")
+	flag.StringVar(&a.OutName, "out-name", ams.DefaultFsOutputName, "split mode file name pattern: {name}, {index}, {txid}")
+	flag.Uint64Var(&a.Wait, "wait", ams.DefaultWaitRounds, "rounds to wait for the submitted transactions confirmation (0 - no waiting)")
+	flag.BoolVar(&a.Json, "json", false, "print the submission result as json")
+	flag.BoolVar(&a.Simulate, "simulate", false, "simulate the signed transactions before sending them")
+	flag.BoolVar(&a.SimulateUnsigned, "simulate-unsigned", false, "simulate the transactions with empty signatures before asking the signers")
+	flag.Var(&a.Multisigs, "msig", "additional multisig account served by the proxy: threshold:addr1,addr2,..")
+	flag.Var(&a.MsigFiles, "msig-file", "multisig descriptor json file of an account served by the proxy")
 	flag.BoolVar(&a.ClipboardUri, "cu", false, "use WalletConnect uri from clipboard")
+	flag.DurationVar(&a.PairTimeout, "pair-timeout", 0, "signers pairing timeout (0 - no timeout)")
+	flag.IntVar(&a.PairTries, "pair-tries", 0, "max signers pairing attempts (0 - unlimited)")
 	flag.Parse()
 
 	err := run(a)
@@ -0,0 +1,633 @@
+package ams
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormat selects how decoded transactions are rendered
+type OutputFormat string
+
+const (
+	OutputText OutputFormat = "text"
+	OutputJSON OutputFormat = "json"
+	OutputYAML OutputFormat = "yaml"
+)
+
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch f := OutputFormat(s); f {
+	case OutputText, OutputJSON, OutputYAML:
+		return f, nil
+	case "":
+		return OutputText, nil
+	}
+
+	return "", errors.Errorf("unknown output format: %s", s)
+}
+
+// DecodedAsset is an asset reference with its metadata when an asset resolver is set
+type DecodedAsset struct {
+	ID   uint64     `json:"id" yaml:"id"`
+	Info *AssetInfo `json:"info,omitempty" yaml:"info,omitempty"`
+	// Text is the human readable form, e.g. "USDC (ASA #31566704)"
+	Text string `json:"text" yaml:"text"`
+}
+
+type DecodedPayment struct {
+	Receiver         string `json:"receiver" yaml:"receiver"`
+	Amount           uint64 `json:"amount" yaml:"amount"`
+	AmountText       string `json:"amount-text" yaml:"amount-text"`
+	CloseRemainderTo string `json:"close-remainder-to,omitempty" yaml:"close-remainder-to,omitempty"`
+}
+
+type DecodedAssetTransfer struct {
+	Asset      DecodedAsset `json:"asset" yaml:"asset"`
+	Receiver   string       `json:"receiver" yaml:"receiver"`
+	Amount     uint64       `json:"amount" yaml:"amount"`
+	AmountText string       `json:"amount-text" yaml:"amount-text"`
+	CloseTo    string       `json:"close-to,omitempty" yaml:"close-to,omitempty"`
+	// ClawbackFrom is the account the asset is revoked from
+	ClawbackFrom string `json:"clawback-from,omitempty" yaml:"clawback-from,omitempty"`
+}
+
+type DecodedAssetParams struct {
+	AssetName     string `json:"asset-name" yaml:"asset-name"`
+	UnitName      string `json:"unit-name" yaml:"unit-name"`
+	Total         uint64 `json:"total" yaml:"total"`
+	Decimals      uint32 `json:"decimals" yaml:"decimals"`
+	DefaultFrozen bool   `json:"default-frozen" yaml:"default-frozen"`
+	URL           string `json:"url,omitempty" yaml:"url,omitempty"`
+	MetadataHash  string `json:"metadata-hash,omitempty" yaml:"metadata-hash,omitempty"`
+}
+
+type DecodedAssetConfig struct {
+	// Action is create, reconfigure or destroy
+	Action string `json:"action" yaml:"action"`
+	// Asset is not set for asset creation
+	Asset  *DecodedAsset       `json:"asset,omitempty" yaml:"asset,omitempty"`
+	Params *DecodedAssetParams `json:"params,omitempty" yaml:"params,omitempty"`
+
+	// an empty role address removes the role
+	Manager  string `json:"manager" yaml:"manager"`
+	Reserve  string `json:"reserve" yaml:"reserve"`
+	Freeze   string `json:"freeze" yaml:"freeze"`
+	Clawback string `json:"clawback" yaml:"clawback"`
+}
+
+type DecodedAssetFreeze struct {
+	Asset  DecodedAsset `json:"asset" yaml:"asset"`
+	Target string       `json:"target" yaml:"target"`
+	Frozen bool         `json:"frozen" yaml:"frozen"`
+}
+
+type DecodedKeyreg struct {
+	Online           bool   `json:"online" yaml:"online"`
+	Nonparticipation bool   `json:"nonparticipation,omitempty" yaml:"nonparticipation,omitempty"`
+	VoteKey          string `json:"vote-key,omitempty" yaml:"vote-key,omitempty"`
+	SelectionKey     string `json:"selection-key,omitempty" yaml:"selection-key,omitempty"`
+	StateProofKey    string `json:"state-proof-key,omitempty" yaml:"state-proof-key,omitempty"`
+	VoteFirst        uint64 `json:"vote-first,omitempty" yaml:"vote-first,omitempty"`
+	VoteLast         uint64 `json:"vote-last,omitempty" yaml:"vote-last,omitempty"`
+	VoteKeyDilution  uint64 `json:"vote-key-dilution,omitempty" yaml:"vote-key-dilution,omitempty"`
+}
+
+type DecodedBox struct {
+	// AppIndex is the foreign app index, 0 for the called app
+	AppIndex uint64 `json:"app-index" yaml:"app-index"`
+	// App is the id of the foreign app, 0 for the called app or an invalid index
+	App  uint64 `json:"app,omitempty" yaml:"app,omitempty"`
+	Name string `json:"name" yaml:"name"`
+}
+
+type DecodedProgram struct {
+	Size int    `json:"size" yaml:"size"`
+	Hash string `json:"sha512-256" yaml:"sha512-256"`
+}
+
+type DecodedSchema struct {
+	NumUint      uint64 `json:"num-uint" yaml:"num-uint"`
+	NumByteSlice uint64 `json:"num-byte-slice" yaml:"num-byte-slice"`
+}
+
+type DecodedAppCall struct {
+	AppID         uint64          `json:"app-id" yaml:"app-id"`
+	OnCompletion  string          `json:"on-completion" yaml:"on-completion"`
+	ABI           *ABICall        `json:"abi,omitempty" yaml:"abi,omitempty"`
+	Args          []string        `json:"args,omitempty" yaml:"args,omitempty"`
+	Accounts      []string        `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	ForeignApps   []uint64        `json:"foreign-apps,omitempty" yaml:"foreign-apps,omitempty"`
+	ForeignAssets []DecodedAsset  `json:"foreign-assets,omitempty" yaml:"foreign-assets,omitempty"`
+	Boxes         []DecodedBox    `json:"boxes,omitempty" yaml:"boxes,omitempty"`
+	Approval      *DecodedProgram `json:"approval-program,omitempty" yaml:"approval-program,omitempty"`
+	Clear         *DecodedProgram `json:"clear-program,omitempty" yaml:"clear-program,omitempty"`
+	GlobalSchema  *DecodedSchema  `json:"global-schema,omitempty" yaml:"global-schema,omitempty"`
+	LocalSchema   *DecodedSchema  `json:"local-schema,omitempty" yaml:"local-schema,omitempty"`
+	ExtraPages    uint32          `json:"extra-program-pages,omitempty" yaml:"extra-program-pages,omitempty"`
+}
+
+// DecodedTxn is the structured form of a transaction; binary fields are base64 encoded
+type DecodedTxn struct {
+	Index int    `json:"index" yaml:"index"`
+	TxID  string `json:"txid" yaml:"txid"`
+	Type  string `json:"type" yaml:"type"`
+
+	Sender   string `json:"sender" yaml:"sender"`
+	AuthAddr string `json:"auth-addr,omitempty" yaml:"auth-addr,omitempty"`
+	// Signature is sig, msig or lsig for signed transactions
+	Signature string `json:"signature,omitempty" yaml:"signature,omitempty"`
+
+	Fee            uint64 `json:"fee" yaml:"fee"`
+	FeeText        string `json:"fee-text" yaml:"fee-text"`
+	FirstValid     uint64 `json:"first-valid" yaml:"first-valid"`
+	LastValid      uint64 `json:"last-valid" yaml:"last-valid"`
+	ValidityRounds uint64 `json:"validity-rounds" yaml:"validity-rounds"`
+	// Validity places the validity window in wall-clock time when a round clock is set
+	Validity    *ValidityEstimate `json:"validity,omitempty" yaml:"validity,omitempty"`
+	GenesisID   string            `json:"genesis-id,omitempty" yaml:"genesis-id,omitempty"`
+	GenesisHash string            `json:"genesis-hash,omitempty" yaml:"genesis-hash,omitempty"`
+	Group       string            `json:"group,omitempty" yaml:"group,omitempty"`
+	Lease       string            `json:"lease,omitempty" yaml:"lease,omitempty"`
+	Note        string            `json:"note,omitempty" yaml:"note,omitempty"`
+	RekeyTo     string            `json:"rekey-to,omitempty" yaml:"rekey-to,omitempty"`
+
+	// NoteDecoded is the terminal safe rendering of the note
+	NoteDecoded *DecodedNote `json:"note-decoded,omitempty" yaml:"note-decoded,omitempty"`
+
+	Payment       *DecodedPayment       `json:"payment,omitempty" yaml:"payment,omitempty"`
+	AssetTransfer *DecodedAssetTransfer `json:"asset-transfer,omitempty" yaml:"asset-transfer,omitempty"`
+	AssetConfig   *DecodedAssetConfig   `json:"asset-config,omitempty" yaml:"asset-config,omitempty"`
+	AssetFreeze   *DecodedAssetFreeze   `json:"asset-freeze,omitempty" yaml:"asset-freeze,omitempty"`
+	Keyreg        *DecodedKeyreg        `json:"keyreg,omitempty" yaml:"keyreg,omitempty"`
+	AppCall       *DecodedAppCall       `json:"app-call,omitempty" yaml:"app-call,omitempty"`
+
+	// Labels are the address book labels of the addresses used in the transaction
+	Labels map[string]AddressLabel `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// Annotations are notes made while decoding, e.g. failed asset lookups
+	Annotations []string      `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	Risks       []RiskFinding `json:"risks,omitempty" yaml:"risks,omitempty"`
+}
+
+// DecodedGroup is a decoded set of transactions with the findings concerning the whole group
+type DecodedGroup struct {
+	Group string        `json:"group,omitempty" yaml:"group,omitempty"`
+	Size  int           `json:"size" yaml:"size"`
+	Txns  []*DecodedTxn `json:"txns" yaml:"txns"`
+	Risks []RiskFinding `json:"risks,omitempty" yaml:"risks,omitempty"`
+}
+
+func encodeBase64(bs []byte) string {
+	return base64.StdEncoding.EncodeToString(bs)
+}
+
+func formatAddress(addr types.Address) string {
+	if addr.IsZero() {
+		return ""
+	}
+
+	return addr.String()
+}
+
+func decodeProgram(program []byte) *DecodedProgram {
+	if len(program) == 0 {
+		return nil
+	}
+
+	hash := sha512.Sum512_256(program)
+
+	return &DecodedProgram{
+		Size: len(program),
+		Hash: hex.EncodeToString(hash[:]),
+	}
+}
+
+func decodeSchema(s types.StateSchema) *DecodedSchema {
+	if s == (types.StateSchema{}) {
+		return nil
+	}
+
+	return &DecodedSchema{
+		NumUint:      s.NumUint,
+		NumByteSlice: s.NumByteSlice,
+	}
+}
+
+func signatureKind(stx types.SignedTxn) string {
+	switch {
+	case stx.Sig != (types.Signature{}):
+		return "sig"
+	case len(stx.Msig.Subsigs) > 0:
+		return "msig"
+	case len(stx.Lsig.Logic) > 0:
+		return "lsig"
+	}
+
+	return ""
+}
+
+func (o *formatOptions) decodeAsset(d *DecodedTxn, id uint64) DecodedAsset {
+	a := DecodedAsset{
+		ID:   id,
+		Text: fmt.Sprintf("ASA #%d", id),
+	}
+
+	if o.assets == nil {
+		return a
+	}
+
+	info, err := o.assets.ResolveAsset(id)
+	switch {
+	case err != nil:
+		a.Text = fmt.Sprintf("ASA #%d (asset lookup failed: %s)", id, err)
+		d.Annotations = append(d.Annotations, fmt.Sprintf("asset lookup failed for ASA #%d: %s", id, err))
+	case info.Unknown:
+		a.Info = &info
+		a.Text = fmt.Sprintf("ASA #%d [!] UNKNOWN OR DESTROYED ASSET", id)
+	default:
+		a.Info = &info
+		a.Text = fmt.Sprintf("%s (ASA #%d)", info.Label(), id)
+	}
+
+	return a
+}
+
+func assetAmountText(amount uint64, a DecodedAsset) string {
+	if a.Info == nil {
+		return fmt.Sprintf("%d base units of %s", amount, a.Text)
+	}
+
+	return FormatAssetAmount(amount, *a.Info)
+}
+
+func (o *formatOptions) decodeAppCall(d *DecodedTxn, txn types.Transaction) *DecodedAppCall {
+	c := &DecodedAppCall{
+		AppID:        uint64(txn.ApplicationID),
+		OnCompletion: formatOnCompletion(txn.OnCompletion),
+		Approval:     decodeProgram(txn.ApprovalProgram),
+		Clear:        decodeProgram(txn.ClearStateProgram),
+		GlobalSchema: decodeSchema(txn.GlobalStateSchema),
+		LocalSchema:  decodeSchema(txn.LocalStateSchema),
+		ExtraPages:   txn.ExtraProgramPages,
+	}
+
+	call, err := o.abi.DecodeAppCall(txn)
+	if err != nil {
+		d.Annotations = append(d.Annotations, fmt.Sprintf("ABI decoding failed: %s", err))
+	}
+	c.ABI = call
+
+	for _, arg := range txn.ApplicationArgs {
+		c.Args = append(c.Args, encodeBase64(arg))
+	}
+
+	for _, acc := range txn.Accounts {
+		c.Accounts = append(c.Accounts, acc.String())
+	}
+
+	for _, app := range txn.ForeignApps {
+		c.ForeignApps = append(c.ForeignApps, uint64(app))
+	}
+
+	for _, asset := range txn.ForeignAssets {
+		c.ForeignAssets = append(c.ForeignAssets, o.decodeAsset(d, uint64(asset)))
+	}
+
+	for _, box := range txn.BoxReferences {
+		b := DecodedBox{
+			AppIndex: box.ForeignAppIdx,
+			Name:     encodeBase64(box.Name),
+		}
+
+		if box.ForeignAppIdx > 0 && int(box.ForeignAppIdx) <= len(txn.ForeignApps) {
+			b.App = uint64(txn.ForeignApps[box.ForeignAppIdx-1])
+		}
+
+		c.Boxes = append(c.Boxes, b)
+	}
+
+	return c
+}
+
+func (o *formatOptions) decodeAssetConfig(d *DecodedTxn, txn types.Transaction) *DecodedAssetConfig {
+	p := txn.AssetParams
+
+	c := &DecodedAssetConfig{
+		Manager:  formatAddress(p.Manager),
+		Reserve:  formatAddress(p.Reserve),
+		Freeze:   formatAddress(p.Freeze),
+		Clawback: formatAddress(p.Clawback),
+	}
+
+	switch {
+	case txn.ConfigAsset == 0:
+		c.Action = "create"
+		c.Params = &DecodedAssetParams{
+			AssetName:     p.AssetName,
+			UnitName:      p.UnitName,
+			Total:         p.Total,
+			Decimals:      p.Decimals,
+			DefaultFrozen: p.DefaultFrozen,
+			URL:           p.URL,
+		}
+
+		if p.MetadataHash != ([types.AssetMetadataHashLen]byte{}) {
+			c.Params.MetadataHash = encodeBase64(p.MetadataHash[:])
+		}
+	case p == (types.AssetParams{}):
+		c.Action = "destroy"
+	default:
+		c.Action = "reconfigure"
+	}
+
+	if txn.ConfigAsset != 0 {
+		a := o.decodeAsset(d, uint64(txn.ConfigAsset))
+		c.Asset = &a
+	}
+
+	return c
+}
+
+func decodeKeyreg(txn types.Transaction) *DecodedKeyreg {
+	k := &DecodedKeyreg{
+		Nonparticipation: txn.Nonparticipation,
+	}
+
+	if txn.VotePK == (types.VotePK{}) && txn.SelectionPK == (types.VRFPK{}) {
+		return k
+	}
+
+	k.Online = true
+	k.VoteKey = encodeBase64(txn.VotePK[:])
+	k.SelectionKey = encodeBase64(txn.SelectionPK[:])
+
+	if txn.StateProofPK != (types.MerkleVerifier{}) {
+		k.StateProofKey = encodeBase64(txn.StateProofPK[:])
+	}
+
+	k.VoteFirst = uint64(txn.VoteFirst)
+	k.VoteLast = uint64(txn.VoteLast)
+	k.VoteKeyDilution = txn.VoteKeyDilution
+
+	return k
+}
+
+func makeFormatOptions(opts []FormatOption) *formatOptions {
+	o := &formatOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// validityRounds is the number of rounds the transaction can be confirmed in; an inverted window has none
+func validityRounds(txn types.Transaction) uint64 {
+	if txn.LastValid < txn.FirstValid {
+		return 0
+	}
+
+	rounds := uint64(txn.LastValid - txn.FirstValid)
+	if rounds == math.MaxUint64 {
+		return rounds
+	}
+
+	return rounds + 1
+}
+
+func (o *formatOptions) decodeTxn(index int, stx types.SignedTxn) *DecodedTxn {
+	txn := stx.Txn
+
+	d := &DecodedTxn{
+		Index:          index,
+		TxID:           crypto.TransactionIDString(txn),
+		Type:           string(txn.Type),
+		Sender:         txn.Sender.String(),
+		AuthAddr:       formatAddress(stx.AuthAddr),
+		Signature:      signatureKind(stx),
+		Fee:            uint64(txn.Fee),
+		FeeText:        FormatAlgos(uint64(txn.Fee)),
+		FirstValid:     uint64(txn.FirstValid),
+		LastValid:      uint64(txn.LastValid),
+		ValidityRounds: validityRounds(txn),
+		GenesisID:      txn.GenesisID,
+		RekeyTo:        formatAddress(txn.RekeyTo),
+	}
+
+	if o.clock != nil {
+		e := o.clock.Estimate(uint64(txn.FirstValid), uint64(txn.LastValid), time.Now())
+		d.Validity = &e
+	}
+
+	if txn.GenesisHash != ([32]byte{}) {
+		d.GenesisHash = encodeBase64(txn.GenesisHash[:])
+	}
+
+	if txn.Group != (types.Digest{}) {
+		d.Group = encodeBase64(txn.Group[:])
+	}
+
+	if txn.Lease != ([32]byte{}) {
+		d.Lease = encodeBase64(txn.Lease[:])
+	}
+
+	if len(txn.Note) > 0 {
+		n := DecodeNote(txn.Note)
+
+		d.Note = encodeBase64(txn.Note)
+		d.NoteDecoded = &n
+
+		if n.Sanitized {
+			d.Annotations = append(d.Annotations, "escape sequences and control characters were removed from the note")
+		}
+	}
+
+	switch txn.Type {
+	case types.PaymentTx:
+		d.Payment = &DecodedPayment{
+			Receiver:         txn.Receiver.String(),
+			Amount:           uint64(txn.Amount),
+			AmountText:       FormatAlgos(uint64(txn.Amount)),
+			CloseRemainderTo: formatAddress(txn.CloseRemainderTo),
+		}
+
+	case types.AssetTransferTx:
+		a := o.decodeAsset(d, uint64(txn.XferAsset))
+		d.AssetTransfer = &DecodedAssetTransfer{
+			Asset:        a,
+			Receiver:     txn.AssetReceiver.String(),
+			Amount:       txn.AssetAmount,
+			AmountText:   assetAmountText(txn.AssetAmount, a),
+			CloseTo:      formatAddress(txn.AssetCloseTo),
+			ClawbackFrom: formatAddress(txn.AssetSender),
+		}
+
+	case types.AssetConfigTx:
+		d.AssetConfig = o.decodeAssetConfig(d, txn)
+
+	case types.AssetFreezeTx:
+		d.AssetFreeze = &DecodedAssetFreeze{
+			Asset:  o.decodeAsset(d, uint64(txn.FreezeAsset)),
+			Target: txn.FreezeAccount.String(),
+			Frozen: txn.AssetFrozen,
+		}
+
+	case types.KeyRegistrationTx:
+		d.Keyreg = decodeKeyreg(txn)
+
+	case types.ApplicationCallTx:
+		d.AppCall = o.decodeAppCall(d, txn)
+	}
+
+	for _, addr := range d.addresses() {
+		if l, ok := o.book.Lookup(addr); ok {
+			if d.Labels == nil {
+				d.Labels = map[string]AddressLabel{}
+			}
+
+			d.Labels[addr] = l
+		}
+	}
+
+	return d
+}
+
+// addresses returns all the addresses the transaction refers to
+func (d *DecodedTxn) addresses() []string {
+	res := []string{d.Sender, d.AuthAddr, d.RekeyTo}
+
+	switch {
+	case d.Payment != nil:
+		res = append(res, d.Payment.Receiver, d.Payment.CloseRemainderTo)
+	case d.AssetTransfer != nil:
+		res = append(res, d.AssetTransfer.Receiver, d.AssetTransfer.CloseTo, d.AssetTransfer.ClawbackFrom)
+	case d.AssetConfig != nil:
+		res = append(res, d.AssetConfig.Manager, d.AssetConfig.Reserve, d.AssetConfig.Freeze, d.AssetConfig.Clawback)
+	case d.AssetFreeze != nil:
+		res = append(res, d.AssetFreeze.Target)
+	case d.AppCall != nil:
+		res = append(res, d.AppCall.Accounts...)
+	}
+
+	return res
+}
+
+// DecodeTxn decodes a single transaction; a risk analyzer option adds its findings
+func DecodeTxn(txn types.Transaction, opts ...FormatOption) *DecodedTxn {
+	o := makeFormatOptions(opts)
+
+	d := o.decodeTxn(0, types.SignedTxn{Txn: txn})
+	if o.risk != nil {
+		d.Risks = o.risk.AnalyzeTxn(0, txn)
+	}
+
+	return d
+}
+
+// DecodeTxnGroup decodes the transactions of a group including their signature kinds and auth addresses
+func DecodeTxnGroup(stxs []types.SignedTxn, opts ...FormatOption) *DecodedGroup {
+	o := makeFormatOptions(opts)
+
+	g := &DecodedGroup{
+		Size: len(stxs),
+	}
+
+	txs := make([]types.Transaction, len(stxs))
+	for i, stx := range stxs {
+		txs[i] = stx.Txn
+		g.Txns = append(g.Txns, o.decodeTxn(i, stx))
+	}
+
+	if len(g.Txns) > 0 {
+		g.Group = g.Txns[0].Group
+	}
+
+	if o.risk != nil {
+		for _, f := range o.risk.Analyze(txs) {
+			if f.Index < 0 {
+				g.Risks = append(g.Risks, f)
+			} else {
+				g.Txns[f.Index].Risks = append(g.Txns[f.Index].Risks, f)
+			}
+		}
+	}
+
+	return g
+}
+
+// Findings returns the findings of all the transactions followed by the group findings
+func (g *DecodedGroup) Findings() []RiskFinding {
+	var res []RiskFinding
+
+	for _, d := range g.Txns {
+		res = append(res, d.Risks...)
+	}
+
+	return append(res, g.Risks...)
+}
+
+func (g *DecodedGroup) Text() string {
+	out := strings.Builder{}
+
+	for _, d := range g.Txns {
+		out.WriteString(fmt.Sprintf("Transaction #%d, id: %s\n", d.Index, d.TxID))
+		out.WriteString(d.Text())
+		out.WriteString("\n")
+	}
+
+	findings := g.Findings()
+	if len(findings) > 0 {
+		out.WriteString("Risk findings:\n")
+		for _, f := range findings {
+			out.WriteString(f.String())
+			out.WriteString("\n")
+		}
+	}
+
+	return out.String()
+}
+
+type textView interface {
+	Text() string
+}
+
+func render(v textView, f OutputFormat) (string, error) {
+	switch f {
+	case OutputText, "":
+		return v.Text(), nil
+	case OutputJSON:
+		bs, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", errors.Wrap(err, "failed to encode json")
+		}
+
+		return string(bs) + "\n", nil
+	case OutputYAML:
+		bs, err := yaml.Marshal(v)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to encode yaml")
+		}
+
+		return string(bs), nil
+	}
+
+	return "", errors.Errorf("unknown output format: %s", f)
+}
+
+func (d *DecodedTxn) Render(f OutputFormat) (string, error) {
+	return render(d, f)
+}
+
+func (g *DecodedGroup) Render(f OutputFormat) (string, error) {
+	return render(g, f)
+}
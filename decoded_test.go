@@ -0,0 +1,71 @@
+package ams
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeTxnGroup(t *testing.T) {
+	stxs := makeTestPayments(t, crypto.GenerateAccount(), 2)
+	other := crypto.GenerateAccount()
+
+	_, err := AssignGroup(stxs)
+	assert.NoError(t, err)
+
+	stxs[1].Txn.RekeyTo = other.Address
+	stxs[1].AuthAddr = other.Address
+	stxs[1].Sig = types.Signature{1}
+
+	risk, err := MakeRiskAnalyzer()
+	assert.NoError(t, err)
+
+	g := DecodeTxnGroup(stxs, WithFormatRiskAnalyzer(risk))
+
+	assert.Equal(t, 2, g.Size)
+	assert.Equal(t, g.Txns[0].Group, g.Group)
+	assert.Equal(t, crypto.TransactionIDString(stxs[0].Txn), g.Txns[0].TxID)
+	assert.Equal(t, uint64(1), g.Txns[1].Payment.Amount)
+	assert.Equal(t, "0.000001 ALGO", g.Txns[1].Payment.AmountText)
+	assert.Equal(t, "0.001000 ALGO", g.Txns[0].FeeText)
+	assert.Equal(t, "sig", g.Txns[1].Signature)
+	assert.Equal(t, other.Address.String(), g.Txns[1].AuthAddr)
+
+	codes := riskCodes(g.Txns[1].Risks)
+	assert.Equal(t, SeverityCritical, codes["rekey"])
+	assert.Empty(t, g.Risks)
+
+	js, err := g.Render(OutputJSON)
+	assert.NoError(t, err)
+
+	var fromJson DecodedGroup
+	assert.NoError(t, json.Unmarshal([]byte(js), &fromJson))
+	assert.Equal(t, g, &fromJson)
+
+	ys, err := g.Render(OutputYAML)
+	assert.NoError(t, err)
+	assert.Contains(t, ys, "rekey-to: "+other.Address.String())
+	assert.Contains(t, ys, "severity: critical")
+
+	var fromYaml DecodedGroup
+	assert.NoError(t, yaml.Unmarshal([]byte(ys), &fromYaml))
+	assert.Equal(t, g, &fromYaml)
+
+	text, err := g.Render(OutputText)
+	assert.NoError(t, err)
+	assert.Contains(t, text, "Transaction #1, id: "+g.Txns[1].TxID)
+	assert.Contains(t, text, "[!!!] REKEY TO: "+other.Address.String())
+	assert.Contains(t, text, "Risk findings:")
+}
+
+func TestDecodeTxnABIYaml(t *testing.T) {
+	arg := ABIArg{Name: "amount", Type: "uint64", Value: json.RawMessage("42")}
+
+	bs, err := yaml.Marshal(arg)
+	assert.NoError(t, err)
+	assert.Equal(t, "name: amount\ntype: uint64\nvalue: 42\n", string(bs))
+}
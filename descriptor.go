@@ -0,0 +1,235 @@
+package ams
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// MultisigDescriptorVersion is the version of the multisig descriptor files written by this package
+const MultisigDescriptorVersion = 1
+
+// maxMultisigMembers is the maximum number of multisig members allowed by the protocol
+const maxMultisigMembers = 255
+
+// MultisigMember is a multisig member address with an optional label
+type MultisigMember struct {
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+}
+
+// MultisigDescriptor describes a multisig account; the member order is part of the account definition
+// and the address is the expected result checked when the descriptor is loaded
+type MultisigDescriptor struct {
+	Version   int              `json:"version"`
+	Label     string           `json:"label,omitempty"`
+	Threshold int              `json:"threshold"`
+	Members   []MultisigMember `json:"members"`
+	Address   string           `json:"address"`
+}
+
+// ValidateMultisig checks that the threshold can be reached and that the members are unique
+func ValidateMultisig(threshold int, addrs []types.Address) error {
+	if len(addrs) > maxMultisigMembers {
+		return errors.Errorf("too many multisig members - got: %d, max: %d", len(addrs), maxMultisigMembers)
+	}
+
+	if threshold < 1 || threshold > len(addrs) {
+		return errors.Errorf("invalid multisig threshold: %d, expected 1..%d", threshold, len(addrs))
+	}
+
+	seen := map[types.Address]int{}
+	for i, addr := range addrs {
+		if j, ok := seen[addr]; ok {
+			return errors.Errorf("duplicate multisig member #%d and #%d: %s", j, i, addr)
+		}
+
+		seen[addr] = i
+	}
+
+	return nil
+}
+
+// MakeMultisigDescriptor builds a descriptor of the members in order and fills in the resulting address
+func MakeMultisigDescriptor(threshold int, members []MultisigMember, label string) (*MultisigDescriptor, error) {
+	d := &MultisigDescriptor{
+		Version:   MultisigDescriptorVersion,
+		Label:     label,
+		Threshold: threshold,
+		Members:   members,
+	}
+
+	ma, err := d.multisig()
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := ma.Address()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multisig address")
+	}
+
+	d.Address = addr.String()
+
+	return d, nil
+}
+
+// ReadMultisigDescriptorFile loads and verifies a descriptor json file
+func ReadMultisigDescriptorFile(path string) (*MultisigDescriptor, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read multisig descriptor file")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.DisallowUnknownFields()
+
+	var d MultisigDescriptor
+	err = dec.Decode(&d)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode multisig descriptor file: %s", path)
+	}
+
+	_, err = d.MultisigAccount()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid multisig descriptor file: %s", path)
+	}
+
+	return &d, nil
+}
+
+func (d *MultisigDescriptor) multisig() (*crypto.MultisigAccount, error) {
+	addrs := make([]types.Address, len(d.Members))
+
+	for i, m := range d.Members {
+		addr, err := types.DecodeAddress(m.Address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid multisig member #%d address: %s", i, m.Address)
+		}
+
+		addrs[i] = addr
+	}
+
+	err := ValidateMultisig(d.Threshold, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	ma, err := crypto.MultisigAccountWithParams(1, uint8(d.Threshold), addrs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build multisig account")
+	}
+
+	return &ma, nil
+}
+
+// MultisigAccount verifies the descriptor and returns its multisig account
+func (d *MultisigDescriptor) MultisigAccount() (*crypto.MultisigAccount, error) {
+	if d.Version != MultisigDescriptorVersion {
+		return nil, errors.Errorf("unsupported multisig descriptor version: %d", d.Version)
+	}
+
+	ma, err := d.multisig()
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := ma.Address()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multisig address")
+	}
+
+	if len(d.Address) == 0 {
+		return nil, errors.Errorf("missing expected multisig address, derived: %s", addr)
+	}
+
+	if addr.String() != d.Address {
+		return nil, errors.Errorf("multisig address mismatch - expected: %s, derived: %s", d.Address, addr)
+	}
+
+	return ma, nil
+}
+
+// Write saves the descriptor as indented json
+func (d *MultisigDescriptor) Write(path string) error {
+	bs, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode multisig descriptor")
+	}
+
+	err = os.WriteFile(path, append(bs, '\n'), 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to write multisig descriptor file")
+	}
+
+	return nil
+}
+
+// Print writes the descriptor in a human readable form
+func (d *MultisigDescriptor) Print() {
+	if len(d.Label) > 0 {
+		fmt.Printf("Multisig: %s (%s)\n", d.Address, d.Label)
+	} else {
+		fmt.Println("Multisig:", d.Address)
+	}
+
+	fmt.Printf("Threshold: %d of %d\n", d.Threshold, len(d.Members))
+
+	for i, m := range d.Members {
+		if len(m.Label) > 0 {
+			fmt.Printf("Member #%d: %s (%s)\n", i, m.Address, m.Label)
+		} else {
+			fmt.Printf("Member #%d: %s\n", i, m.Address)
+		}
+	}
+}
+
+// CheckMultisigDescriptors verifies that the multisig signatures of the transactions signed by one of the
+// described accounts use the described members, order and threshold
+func CheckMultisigDescriptors(stxs []types.SignedTxn, descs []*MultisigDescriptor) error {
+	if len(descs) == 0 {
+		return nil
+	}
+
+	byAddr := map[string]*MultisigDescriptor{}
+	for _, d := range descs {
+		byAddr[d.Address] = d
+	}
+
+	for i, stx := range stxs {
+		signer := stx.Txn.Sender
+		if !stx.AuthAddr.IsZero() {
+			signer = stx.AuthAddr
+		}
+
+		d, ok := byAddr[signer.String()]
+		if !ok {
+			continue
+		}
+
+		if stx.Msig.Blank() {
+			continue
+		}
+
+		ma, err := crypto.MultisigAccountFromSig(stx.Msig)
+		if err != nil {
+			return errors.Wrapf(err, "invalid multisig of transaction #%d", i)
+		}
+
+		addr, err := ma.Address()
+		if err != nil {
+			return errors.Wrapf(err, "invalid multisig of transaction #%d", i)
+		}
+
+		if addr.String() != d.Address {
+			return errors.Errorf("multisig of transaction #%d does not match the descriptor - expected: %s, got: %s", i, d.Address, addr)
+		}
+	}
+
+	return nil
+}
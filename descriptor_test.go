@@ -0,0 +1,125 @@
+package ams
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMultisig(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+
+	assert.NoError(t, ValidateMultisig(2, []types.Address{acc1.Address, acc2.Address}))
+	assert.Error(t, ValidateMultisig(0, []types.Address{acc1.Address, acc2.Address}))
+	assert.Error(t, ValidateMultisig(3, []types.Address{acc1.Address, acc2.Address}))
+	assert.Error(t, ValidateMultisig(1, []types.Address{acc1.Address, acc2.Address, acc1.Address}))
+
+	_, err := MakeAddressSource(
+		WithAddressString(acc1.Address.String()+","+acc1.Address.String()),
+		WithAddressThreshold(1),
+	)
+	assert.Error(t, err)
+}
+
+func TestMultisigDescriptorFile(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+
+	members := []MultisigMember{
+		{Address: acc1.Address.String(), Label: "alice"},
+		{Address: acc2.Address.String()},
+	}
+
+	d, err := MakeMultisigDescriptor(2, members, "treasury")
+	assert.NoError(t, err)
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+	assert.Equal(t, maddr.String(), d.Address)
+
+	p := filepath.Join(t.TempDir(), "msig.json")
+	assert.NoError(t, d.Write(p))
+
+	r, err := ReadMultisigDescriptorFile(p)
+	assert.NoError(t, err)
+	assert.Equal(t, d, r)
+
+	as, err := MakeAddressSource(WithAddressDescriptorFile(p))
+	assert.NoError(t, err)
+	assert.Equal(t, d.Address, as.Address())
+	assert.Equal(t, ma, *as.Multisig())
+
+	_, err = MakeAddressSource(WithAddressDescriptor(d), WithAddressString(acc1.Address.String()))
+	assert.Error(t, err)
+
+	book, err := MakeAddressBook(WithAddressBookDescriptor(d))
+	assert.NoError(t, err)
+	assert.Equal(t, d.Address+" (treasury)", book.Format(d.Address))
+
+	// reordered members derive a different address
+	swapped := *d
+	swapped.Members = []MultisigMember{members[1], members[0]}
+	assert.NoError(t, swapped.Write(p))
+
+	_, err = ReadMultisigDescriptorFile(p)
+	assert.Error(t, err)
+
+	unsupported := *d
+	unsupported.Version = MultisigDescriptorVersion + 1
+	assert.NoError(t, unsupported.Write(p))
+
+	_, err = ReadMultisigDescriptorFile(p)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(p, []byte(`{"version":1,"threshold":1,"members":[],"address":"","extra":1}`), 0644))
+
+	_, err = ReadMultisigDescriptorFile(p)
+	assert.Error(t, err)
+}
+
+func TestCheckMultisigDescriptors(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+
+	d, err := MakeMultisigDescriptor(1, []MultisigMember{
+		{Address: acc1.Address.String()},
+		{Address: acc2.Address.String()},
+	}, "")
+	assert.NoError(t, err)
+
+	ma, err := d.MultisigAccount()
+	assert.NoError(t, err)
+
+	other, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+
+	tx, err := transaction.MakePaymentTxn(d.Address, d.Address, 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	_, bs, err := crypto.SignMultisigTransaction(acc1.PrivateKey, *ma, tx)
+	assert.NoError(t, err)
+
+	var stx types.SignedTxn
+	assert.NoError(t, msgpack.Decode(bs, &stx))
+
+	assert.NoError(t, CheckMultisigDescriptors([]types.SignedTxn{stx}, []*MultisigDescriptor{d}))
+
+	// a signature with a different threshold targets another account
+	_, bs, err = crypto.SignMultisigTransaction(acc1.PrivateKey, other, tx)
+	assert.NoError(t, err)
+	assert.NoError(t, msgpack.Decode(bs, &stx))
+
+	stx.AuthAddr = types.Address{}
+
+	assert.Error(t, CheckMultisigDescriptors([]types.SignedTxn{stx}, []*MultisigDescriptor{d}))
+}
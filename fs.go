@@ -5,9 +5,14 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/crypto"
 	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
 	"github.com/algorand/go-algorand-sdk/types"
 	"github.com/dragmz/wc"
@@ -18,12 +23,49 @@ type FsRunner struct {
 	paths []string
 	used  atomic.Bool
 
-	s     wc.Signer
-	debug bool
+	s           wc.Signer
+	debug       bool
+	debugFormat OutputFormat
 
 	ac *algod.Client
+
+	out     string
+	outMode FsOutputMode
+	outName string
+
+	wait uint64
+	json bool
+
+	sim *Simulator
+
+	inbox string
+	poll  time.Duration
+
+	group    bool
+	poolFees bool
+	refresh  bool
+
+	descPaths []string
+	descs     []*MultisigDescriptor
+
+	book *AddressBook
 }
 
+// FsOutputMode selects how the signed transactions are written when an output path is set
+type FsOutputMode string
+
+const (
+	// FsOutputGroup writes the whole group as one concatenated msgpack file
+	FsOutputGroup FsOutputMode = "group"
+	// FsOutputSplit writes one file per transaction into the output directory
+	FsOutputSplit FsOutputMode = "split"
+)
+
+const DefaultFsPollInterval = 5 * time.Second
+
+// DefaultFsOutputName is the file name pattern used in the split output mode
+const DefaultFsOutputName = "{name}-{index}.stxn"
+
 type FsRunnerOption func(r *FsRunner)
 
 func WithFsRunnerAlgod(ac *algod.Client) FsRunnerOption {
@@ -38,102 +80,450 @@ func WithFsRunnerDebug(debug bool) FsRunnerOption {
 	}
 }
 
+// WithFsRunnerDebugFormat sets the format of the decoded transactions printed in the debug mode
+func WithFsRunnerDebugFormat(f OutputFormat) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.debugFormat = f
+	}
+}
+
 func WithFsRunnerSigner(s wc.Signer) FsRunnerOption {
 	return func(r *FsRunner) {
 		r.s = s
 	}
 }
 
+// WithFsRunnerOutput makes the runner write the signed transactions to path instead of sending them to algod
+func WithFsRunnerOutput(path string) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.out = path
+	}
+}
+
+func WithFsRunnerOutputMode(mode FsOutputMode) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.outMode = mode
+	}
+}
+
+// WithFsRunnerOutputName sets the split mode file name pattern; {name}, {index} and {txid} are replaced
+// with the input file name without extension, the transaction index in the group and the transaction id
+func WithFsRunnerOutputName(pattern string) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.outName = pattern
+	}
+}
+
+// WithFsRunnerWaitRounds sets the number of rounds to wait for the submitted group confirmation (0 - no waiting)
+func WithFsRunnerWaitRounds(rounds uint64) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.wait = rounds
+	}
+}
+
+// WithFsRunnerJson prints the submission result as json
+func WithFsRunnerJson(json bool) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.json = json
+	}
+}
+
+// WithFsRunnerSimulator simulates the group before it is sent; a simulator allowing empty signatures runs before signing
+func WithFsRunnerSimulator(sim *Simulator) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.sim = sim
+	}
+}
+
+// WithFsRunnerInbox makes the runner watch the directory and process every new file in it as a separate group.
+// Processed files are moved to the done or failed subdirectory; the output path is used as the outbox directory.
+func WithFsRunnerInbox(dir string) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.inbox = dir
+	}
+}
+
+func WithFsRunnerPollInterval(d time.Duration) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.poll = d
+	}
+}
+
+// WithFsRunnerAssignGroup assigns a group id to the unsigned input transactions
+func WithFsRunnerAssignGroup(group bool) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.group = group
+	}
+}
+
+// WithFsRunnerPoolFees moves the group fees of each sender to its first transaction using the algod suggested params
+func WithFsRunnerPoolFees(pool bool) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.poolFees = pool
+	}
+}
+
+// WithFsRunnerRefresh re-stamps the validity window, genesis and fees of the unsigned input transactions
+func WithFsRunnerRefresh(refresh bool) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.refresh = refresh
+	}
+}
+
+// WithFsRunnerAddressBook labels the addresses of the input transactions and flags the ones resembling labeled addresses
+func WithFsRunnerAddressBook(b *AddressBook) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.book = b
+	}
+}
+
+// WithFsRunnerMultisigDescriptorFiles loads multisig descriptors used to verify the multisig signatures of the inputs
+func WithFsRunnerMultisigDescriptorFiles(paths []string) FsRunnerOption {
+	return func(r *FsRunner) {
+		r.descPaths = paths
+	}
+}
+
 func MakeFsRunner(paths []string, opts ...FsRunnerOption) (*FsRunner, error) {
 	r := &FsRunner{
-		paths: paths,
+		paths:       paths,
+		debugFormat: OutputText,
+		outMode:     FsOutputGroup,
+		outName:     DefaultFsOutputName,
+		wait:        DefaultWaitRounds,
+		poll:        DefaultFsPollInterval,
 	}
 
 	for _, opt := range opts {
 		opt(r)
 	}
 
+	switch r.outMode {
+	case FsOutputGroup, FsOutputSplit:
+	default:
+		return nil, errors.Errorf("unknown output mode: %s", r.outMode)
+	}
+
+	if len(r.outName) == 0 {
+		return nil, errors.New("empty output name pattern")
+	}
+
+	switch r.debugFormat {
+	case OutputText, OutputJSON, OutputYAML:
+	default:
+		return nil, errors.Errorf("unknown debug format: %s", r.debugFormat)
+	}
+
+	for _, p := range r.descPaths {
+		d, err := ReadMultisigDescriptorFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		r.descs = append(r.descs, d)
+	}
+
 	return r, nil
 }
 
-func (r *FsRunner) ReadRequestFromFiles(paths []string) (*wc.AlgoSignRequest, error) {
-	var txs []types.Transaction
+func (r *FsRunner) readInputs(paths []string) ([]InputTxn, error) {
+	var res []InputTxn
 
 	for _, p := range paths {
-		bs, err := os.ReadFile(p)
+		txs, err := ReadTxnFile(p)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to read transaction from file")
+			return nil, errors.Wrap(err, "failed to read transactions from file")
+		}
+
+		for _, tx := range txs {
+			fmt.Printf("Transaction #%d: %s (%s), id: %s, sender: %s\n", len(res), tx.Location(), tx.Format, crypto.TransactionIDString(tx.Stxn.Txn), r.book.Format(tx.Stxn.Txn.Sender.String()))
+			res = append(res, tx)
 		}
+	}
 
-		var ustx types.SignedTxn
-		err = msgpack.Decode(bs, &ustx)
+	if r.debug {
+		text, err := DecodeTxnGroup(InputSignedTxns(res), WithFormatAddressBook(r.book)).Render(r.debugFormat)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to decode transaction msgpack")
+			return nil, err
 		}
 
-		// TODO: check if signed
+		fmt.Print(text)
+	}
+
+	return res, nil
+}
+
+// ReadTxnsFromFiles reads the transactions keeping any signatures that are already present
+func (r *FsRunner) ReadTxnsFromFiles(paths []string) ([]types.SignedTxn, error) {
+	txs, err := r.readInputs(paths)
+	if err != nil {
+		return nil, err
+	}
 
-		if r.debug {
-			fmt.Printf("Path: %s, tx: %+v\n", p, ustx)
+	return InputSignedTxns(txs), nil
+}
+
+func fsOutputName(pattern string, source string, index int, stx types.SignedTxn) string {
+	name := filepath.Base(source)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	return strings.NewReplacer(
+		"{name}", name,
+		"{index}", strconv.Itoa(index),
+		"{txid}", crypto.TransactionIDString(stx.Txn),
+	).Replace(pattern)
+}
+
+// writeOutput writes the signed transactions according to the output mode and returns the written paths
+func (r *FsRunner) writeOutput(out string, inputs []InputTxn, signed [][]byte) ([]string, error) {
+	if r.outMode == FsOutputGroup {
+		var group []byte
+		for _, bs := range signed {
+			group = append(group, bs...)
 		}
 
-		txs = append(txs, ustx.Txn)
+		err := os.WriteFile(out, group, 0644)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to write signed group")
+		}
+
+		return []string{out}, nil
+	}
+
+	err := os.MkdirAll(out, 0755)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create output directory")
+	}
+
+	var paths []string
+	used := map[string]bool{}
+
+	for i, bs := range signed {
+		p := filepath.Join(out, fsOutputName(r.outName, inputs[i].Source, i, inputs[i].Stxn))
+		if used[p] {
+			return nil, errors.Errorf("output name pattern produces duplicate file names: %s", p)
+		}
+		used[p] = true
+
+		err := os.WriteFile(p, bs, 0644)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to write signed transaction - index: %d", i)
+		}
+
+		paths = append(paths, p)
 	}
 
-	req := wc.AlgoSignRequest{
-		Params: [][]wc.AlgoSignParams{
-			{},
-		},
+	return paths, nil
+}
+
+func (r *FsRunner) ReadRequestFromFiles(paths []string) (*wc.AlgoSignRequest, error) {
+	stxs, err := r.ReadTxnsFromFiles(paths)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, txn := range txs {
-		b64 := base64.StdEncoding.EncodeToString(msgpack.Encode(txn))
-		req.Params[0] = append(req.Params[0], wc.AlgoSignParams{
-			TxnBase64: b64,
-		})
+	txs := make([]types.Transaction, len(stxs))
+	for i, stx := range stxs {
+		txs[i] = stx.Txn
 	}
 
+	req := MakeSignRequest(txs)
+
 	return &req, nil
 }
 
+// prepare refreshes the transactions, pools the fees and assigns the group id as configured, printing the changes
+func (r *FsRunner) prepare(stxs []types.SignedTxn) error {
+	grouped := r.group || r.poolFees
+	for _, stx := range stxs {
+		if stx.Txn.Group != (types.Digest{}) {
+			grouped = true
+		}
+	}
+
+	// independent transactions are not limited by the group size
+	if grouped && len(stxs) > MaxGroupSize {
+		return errors.Errorf("too many transactions in a group - got: %d, max: %d", len(stxs), MaxGroupSize)
+	}
+
+	var changes []TxnChange
+
+	pool := r.poolFees && len(stxs) > 1
+
+	// pooled fees invalidate an existing group id, refresh regroups on its own
+	var pooled bool
+
+	if pool || r.refresh {
+		if r.ac == nil {
+			return errors.New("fee pooling and refresh require an algod client")
+		}
+
+		sp, err := r.ac.SuggestedParams().Do(context.Background())
+		if err != nil {
+			return errors.Wrap(err, "failed to get suggested params")
+		}
+
+		if r.refresh {
+			rcs, err := Refresh(stxs, sp)
+			if err != nil {
+				return errors.Wrap(err, "failed to refresh transactions")
+			}
+
+			changes = append(changes, rcs...)
+		}
+
+		if pool {
+			fcs, err := PoolFees(stxs, sp)
+			if err != nil {
+				return errors.Wrap(err, "failed to pool fees")
+			}
+
+			changes = append(changes, fcs...)
+			pooled = len(fcs) > 0
+		}
+	}
+
+	if r.group || (pooled && stxs[0].Txn.Group != (types.Digest{})) {
+		gcs, err := AssignGroup(stxs)
+		if err != nil {
+			return errors.Wrap(err, "failed to assign group")
+		}
+
+		changes = append(changes, gcs...)
+	}
+
+	if len(changes) > 0 {
+		fmt.Println("Transactions changed before signing:")
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+
+	return nil
+}
+
+func (r *FsRunner) simulate(stxs []types.SignedTxn) error {
+	res, err := r.sim.Simulate(stxs)
+	if err != nil {
+		return errors.Wrap(err, "failed to simulate transactions")
+	}
+
+	if !r.json {
+		res.Print()
+	}
+
+	if !res.WouldSucceed {
+		return errors.Errorf("simulation failed - transaction: %d, message: %s", res.FailedIndex(), res.FailureMessage)
+	}
+
+	return nil
+}
+
 func (r *FsRunner) Run() error {
 	if r.used.Swap(true) {
 		return nil
 	}
 
-	req, err := r.ReadRequestFromFiles(r.paths)
+	if len(r.inbox) > 0 {
+		return r.runInbox()
+	}
+
+	return r.process(r.paths, r.out)
+}
+
+// process signs the transactions read from paths and writes them to out or sends them if out is empty
+func (r *FsRunner) process(paths []string, out string) error {
+	inputs, err := r.readInputs(paths)
 	if err != nil {
 		return errors.Wrap(err, "failed to read transactions from files")
 	}
 
-	resp, err := r.s.Sign(*req)
+	stxs := InputSignedTxns(inputs)
+
+	err = CheckMultisigDescriptors(stxs, r.descs)
+	if err != nil {
+		return errors.Wrap(err, "failed to verify multisig signatures")
+	}
+
+	err = r.prepare(stxs)
+	if err != nil {
+		return err
+	}
+
+	for i := range inputs {
+		inputs[i].Stxn = stxs[i]
+	}
+
+	for i, stx := range stxs {
+		switch {
+		case IsFullySigned(stx):
+			fmt.Printf("Transaction #%d is already signed\n", i)
+		case IsSigned(stx):
+			fmt.Printf("Transaction #%d is partially signed\n", i)
+		}
+	}
+
+	if r.sim != nil && r.sim.AllowsEmptySignatures() {
+		err = r.simulate(stxs)
+		if err != nil {
+			return err
+		}
+	}
+
+	signed, err := SignMissing(r.s, stxs)
 	if err != nil {
 		return errors.Wrap(err, "failed to sign transactions")
 	}
 
-	fmt.Println("Sending signed transactions..")
+	if r.sim != nil && !r.sim.AllowsEmptySignatures() {
+		sstxs := make([]types.SignedTxn, len(signed))
+		for i, bs := range signed {
+			err = msgpack.Decode(bs, &sstxs[i])
+			if err != nil {
+				return errors.Wrap(err, "failed to decode signed transaction")
+			}
+		}
 
-	if r.debug {
-		fmt.Println(resp)
+		err = r.simulate(sstxs)
+		if err != nil {
+			return err
+		}
 	}
 
-	var group []byte
+	if r.debug {
+		for _, bs := range signed {
+			fmt.Println(base64.StdEncoding.EncodeToString(bs))
+		}
+	}
 
-	for _, b64 := range resp.Result {
-		bs, err := base64.StdEncoding.DecodeString(b64)
+	if len(out) > 0 {
+		written, err := r.writeOutput(out, inputs, signed)
 		if err != nil {
-			return errors.Wrap(err, "failed to decode transaction")
+			return errors.Wrap(err, "failed to write signed transactions")
+		}
+
+		for _, p := range written {
+			fmt.Println("Written:", p)
 		}
 
-		group = append(group, bs...)
+		return nil
 	}
 
-	id, err := r.ac.SendRawTransaction(group).Do(context.Background())
-	if err != nil {
-		return errors.Wrap(err, "failed to send transactions")
+	if !r.json {
+		fmt.Println("Sending signed transactions..")
 	}
 
-	fmt.Println("Id:", id)
+	res, err := SubmitGroup(r.ac, signed, r.wait)
+	if res != nil {
+		perr := PrintSubmitResult(res, r.json)
+		if perr != nil {
+			return perr
+		}
+	}
+
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
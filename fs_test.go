@@ -0,0 +1,185 @@
+package ams
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFsTestInputs(t *testing.T, dir string, acc crypto.Account) []string {
+	var paths []string
+
+	for i, name := range []string{"a.txn", "b.txn"} {
+		tx, err := transaction.MakePaymentTxn(acc.Address.String(), acc.Address.String(), 1000, uint64(i), 1000, 2000, nil, "", "test", []byte("test"))
+		assert.NoError(t, err)
+
+		p := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(p, msgpack.Encode(types.SignedTxn{Txn: tx}), 0644))
+
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+func TestFsRunnerOutputGroup(t *testing.T) {
+	dir := t.TempDir()
+	acc := crypto.GenerateAccount()
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	out := filepath.Join(dir, "group.stxn")
+
+	r, err := MakeFsRunner(writeFsTestInputs(t, dir, acc),
+		WithFsRunnerSigner(s),
+		WithFsRunnerOutput(out),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Run())
+
+	txs, err := ReadTxnFile(out)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2)
+
+	for _, tx := range txs {
+		assert.True(t, IsFullySigned(tx.Stxn))
+	}
+}
+
+func TestFsRunnerOutputSplit(t *testing.T) {
+	dir := t.TempDir()
+	acc := crypto.GenerateAccount()
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	out := filepath.Join(dir, "out")
+
+	r, err := MakeFsRunner(writeFsTestInputs(t, dir, acc),
+		WithFsRunnerSigner(s),
+		WithFsRunnerOutput(out),
+		WithFsRunnerOutputMode(FsOutputSplit),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Run())
+
+	for _, name := range []string{"a-0.stxn", "b-1.stxn"} {
+		txs, err := ReadTxnFile(filepath.Join(out, name))
+		assert.NoError(t, err)
+		assert.Len(t, txs, 1)
+		assert.True(t, IsFullySigned(txs[0].Stxn))
+	}
+
+	_, err = MakeFsRunner(nil, WithFsRunnerOutputMode("bogus"))
+	assert.Error(t, err)
+}
+
+func TestFsRunnerInbox(t *testing.T) {
+	dir := t.TempDir()
+	acc := crypto.GenerateAccount()
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	inbox := filepath.Join(dir, "inbox")
+	outbox := filepath.Join(dir, "outbox")
+	assert.NoError(t, os.MkdirAll(inbox, 0755))
+
+	writeFsTestInputs(t, inbox, acc)
+	assert.NoError(t, os.WriteFile(filepath.Join(inbox, "bad.txn"), []byte{0xff, 0x00}, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(inbox, "pending.tmp"), []byte{0xff}, 0644))
+
+	r, err := MakeFsRunner(nil,
+		WithFsRunnerSigner(s),
+		WithFsRunnerInbox(inbox),
+		WithFsRunnerOutput(outbox),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, r.processInbox())
+
+	for _, name := range []string{"a.stxn", "b.stxn"} {
+		txs, err := ReadTxnFile(filepath.Join(outbox, name))
+		assert.NoError(t, err)
+		assert.True(t, IsFullySigned(txs[0].Stxn))
+	}
+
+	assert.FileExists(t, filepath.Join(inbox, "done", "a.txn"))
+	assert.FileExists(t, filepath.Join(inbox, "done", "b.txn"))
+	assert.FileExists(t, filepath.Join(inbox, "failed", "bad.txn"))
+	assert.FileExists(t, filepath.Join(inbox, "failed", "bad.txn.error"))
+	assert.FileExists(t, filepath.Join(inbox, "pending.tmp"))
+	assert.NoFileExists(t, filepath.Join(inbox, "a.txn"))
+}
+
+func TestFsRunnerAssignGroup(t *testing.T) {
+	dir := t.TempDir()
+	acc := crypto.GenerateAccount()
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	out := filepath.Join(dir, "group.stxn")
+
+	r, err := MakeFsRunner(writeFsTestInputs(t, dir, acc),
+		WithFsRunnerSigner(s),
+		WithFsRunnerOutput(out),
+		WithFsRunnerAssignGroup(true),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Run())
+
+	txs, err := ReadTxnFile(out)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 2)
+	assert.NotEqual(t, types.Digest{}, txs[0].Stxn.Txn.Group)
+	assert.Equal(t, txs[0].Stxn.Txn.Group, txs[1].Stxn.Txn.Group)
+	assert.True(t, IsFullySigned(txs[1].Stxn))
+}
+
+func TestFsRunnerUngroupedOverGroupSize(t *testing.T) {
+	dir := t.TempDir()
+	acc := crypto.GenerateAccount()
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	var paths []string
+	for i := 0; i <= MaxGroupSize; i++ {
+		tx, err := transaction.MakePaymentTxn(acc.Address.String(), acc.Address.String(), 1000, uint64(i), 1000, 2000, nil, "", "test", []byte("test"))
+		assert.NoError(t, err)
+
+		p := filepath.Join(dir, fmt.Sprintf("%02d.txn", i))
+		assert.NoError(t, os.WriteFile(p, msgpack.Encode(types.SignedTxn{Txn: tx}), 0644))
+
+		paths = append(paths, p)
+	}
+
+	out := filepath.Join(dir, "out.stxn")
+
+	r, err := MakeFsRunner(paths,
+		WithFsRunnerSigner(s),
+		WithFsRunnerOutput(out),
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Run())
+
+	txs, err := ReadTxnFile(out)
+	assert.NoError(t, err)
+	assert.Len(t, txs, MaxGroupSize+1)
+
+	r, err = MakeFsRunner(paths,
+		WithFsRunnerSigner(s),
+		WithFsRunnerOutput(out),
+		WithFsRunnerAssignGroup(true),
+	)
+	assert.NoError(t, err)
+	assert.Error(t, r.Run())
+}
@@ -11,9 +11,11 @@ require (
 	github.com/stretchr/testify v1.8.1
 	golang.org/x/crypto v0.6.0
 	golang.org/x/term v0.5.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/algorand/avm-abi v0.1.1 // indirect
 	github.com/algorand/go-codec/codec v1.1.9 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
@@ -23,5 +25,7 @@ require (
 	github.com/yeqown/go-qrcode/v2 v2.2.1 // indirect
 	github.com/yeqown/reedsolomon v1.0.0 // indirect
 	golang.org/x/sys v0.5.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// carries Conn.Close on top of v0.0.0-20230226222146-9f7d66fb732f until it is released upstream
+replace github.com/dragmz/wc => ./third_party/wc
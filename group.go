@@ -0,0 +1,180 @@
+package ams
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// MaxGroupSize is the maximum number of transactions in an atomic group
+const MaxGroupSize = 16
+
+// TxnChange describes a modification made to a transaction before signing
+type TxnChange struct {
+	Index int
+	Field string
+	Old   string
+	New   string
+}
+
+func (c TxnChange) String() string {
+	return fmt.Sprintf("Transaction #%d: %s: %s -> %s", c.Index, c.Field, c.Old, c.New)
+}
+
+func formatDigest(gid types.Digest) string {
+	if gid == (types.Digest{}) {
+		return "none"
+	}
+
+	return base64.StdEncoding.EncodeToString(gid[:])
+}
+
+func checkUnsigned(stxs []types.SignedTxn) error {
+	for i, stx := range stxs {
+		if IsSigned(stx) {
+			return errors.Errorf("cannot modify signed transaction #%d", i)
+		}
+	}
+
+	return nil
+}
+
+// AssignGroup sets the group id of the transactions so that they are executed atomically
+func AssignGroup(stxs []types.SignedTxn) ([]TxnChange, error) {
+	if len(stxs) > MaxGroupSize {
+		return nil, errors.Errorf("too many transactions in a group - got: %d, max: %d", len(stxs), MaxGroupSize)
+	}
+
+	if len(stxs) < 2 {
+		return nil, nil
+	}
+
+	txs := make([]types.Transaction, len(stxs))
+	for i, stx := range stxs {
+		txs[i] = stx.Txn
+		txs[i].Group = types.Digest{}
+	}
+
+	gid, err := crypto.ComputeGroupID(txs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compute group id")
+	}
+
+	var changes []TxnChange
+	for i, stx := range stxs {
+		if stx.Txn.Group != gid {
+			changes = append(changes, TxnChange{
+				Index: i,
+				Field: "group",
+				Old:   formatDigest(stx.Txn.Group),
+				New:   formatDigest(gid),
+			})
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	err = checkUnsigned(stxs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stxs {
+		stxs[i].Txn.Group = gid
+	}
+
+	return changes, nil
+}
+
+// suggestedFee returns the fee the suggested params require for the transaction, either flat or per byte
+func suggestedFee(txn types.Transaction, sp types.SuggestedParams) (uint64, error) {
+	fee := uint64(sp.Fee)
+
+	if !sp.FlatFee {
+		size, err := transaction.EstimateSize(txn)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to estimate transaction size")
+		}
+
+		fee *= size
+	}
+
+	if fee < sp.MinFee {
+		fee = sp.MinFee
+	}
+
+	return fee, nil
+}
+
+// PoolFees moves the fees of every sender in the group to the sender's first transaction so that no account
+// pays for the others. The pooled fee covers the suggested fee of every transaction of the sender and never gets
+// lower than its current total so that fees reserved for inner transactions are kept.
+func PoolFees(stxs []types.SignedTxn, sp types.SuggestedParams) ([]TxnChange, error) {
+	if len(stxs) < 2 {
+		return nil, nil
+	}
+
+	// payer is the index of the first transaction of every sender
+	payer := map[types.Address]int{}
+	required := map[types.Address]uint64{}
+	current := map[types.Address]uint64{}
+
+	for i, stx := range stxs {
+		fee, err := suggestedFee(stx.Txn, sp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compute suggested fee - index: %d", i)
+		}
+
+		sender := stx.Txn.Sender
+		if _, ok := payer[sender]; !ok {
+			payer[sender] = i
+		}
+
+		required[sender] += fee
+		current[sender] += uint64(stx.Txn.Fee)
+	}
+
+	fees := make([]uint64, len(stxs))
+
+	for sender, i := range payer {
+		fee := required[sender]
+		if current[sender] > fee {
+			fee = current[sender]
+		}
+
+		fees[i] = fee
+	}
+
+	var changes []TxnChange
+	for i, stx := range stxs {
+		if uint64(stx.Txn.Fee) != fees[i] {
+			changes = append(changes, TxnChange{
+				Index: i,
+				Field: "fee",
+				Old:   fmt.Sprintf("%d microALGO", stx.Txn.Fee),
+				New:   fmt.Sprintf("%d microALGO", fees[i]),
+			})
+		}
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	err := checkUnsigned(stxs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stxs {
+		stxs[i].Txn.Fee = types.MicroAlgos(fees[i])
+	}
+
+	return changes, nil
+}
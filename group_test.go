@@ -0,0 +1,86 @@
+package ams
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignGroup(t *testing.T) {
+	stxs := makeTestPayments(t, crypto.GenerateAccount(), 3)
+
+	changes, err := AssignGroup(stxs)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	var txs []types.Transaction
+	for _, stx := range stxs {
+		tx := stx.Txn
+		tx.Group = types.Digest{}
+		txs = append(txs, tx)
+	}
+
+	gid, err := crypto.ComputeGroupID(txs)
+	assert.NoError(t, err)
+
+	for _, stx := range stxs {
+		assert.Equal(t, gid, stx.Txn.Group)
+	}
+
+	changes, err = AssignGroup(stxs)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = AssignGroup(makeTestPayments(t, crypto.GenerateAccount(), MaxGroupSize+1))
+	assert.Error(t, err)
+
+	signed := makeTestPayments(t, crypto.GenerateAccount(), 2)
+	signed[1].Sig = types.Signature{1}
+
+	_, err = AssignGroup(signed)
+	assert.Error(t, err)
+}
+
+func TestPoolFees(t *testing.T) {
+	stxs := makeTestPayments(t, crypto.GenerateAccount(), 3)
+
+	changes, err := PoolFees(stxs, types.SuggestedParams{Fee: 0, MinFee: 1000})
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	assert.Equal(t, types.MicroAlgos(3000), stxs[0].Txn.Fee)
+	assert.Equal(t, types.MicroAlgos(0), stxs[1].Txn.Fee)
+	assert.Equal(t, types.MicroAlgos(0), stxs[2].Txn.Fee)
+
+	changes, err = PoolFees(stxs, types.SuggestedParams{Fee: 0, MinFee: 1000})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestPoolFeesPerSender(t *testing.T) {
+	stxs := append(makeTestPayments(t, crypto.GenerateAccount(), 2), makeTestPayments(t, crypto.GenerateAccount(), 1)...)
+	stxs = append(stxs, makeTestPayments(t, crypto.GenerateAccount(), 1)...)
+	stxs[3].Txn.Sender = stxs[0].Txn.Sender
+
+	_, err := PoolFees(stxs, types.SuggestedParams{Fee: 0, MinFee: 1000})
+	assert.NoError(t, err)
+
+	// the other sender keeps paying its own fee
+	assert.Equal(t, types.MicroAlgos(3000), stxs[0].Txn.Fee)
+	assert.Equal(t, types.MicroAlgos(0), stxs[1].Txn.Fee)
+	assert.Equal(t, types.MicroAlgos(1000), stxs[2].Txn.Fee)
+	assert.Equal(t, types.MicroAlgos(0), stxs[3].Txn.Fee)
+}
+
+func TestPoolFeesFlatFee(t *testing.T) {
+	stxs := makeTestPayments(t, crypto.GenerateAccount(), 2)
+
+	// a flat fee is not multiplied by the transaction size
+	_, err := PoolFees(stxs, types.SuggestedParams{Fee: 2000, FlatFee: true, MinFee: 1000})
+	assert.NoError(t, err)
+
+	assert.Equal(t, types.MicroAlgos(4000), stxs[0].Txn.Fee)
+	assert.Equal(t, types.MicroAlgos(0), stxs[1].Txn.Fee)
+}
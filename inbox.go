@@ -0,0 +1,136 @@
+package ams
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	inboxDoneDir   = "done"
+	inboxFailedDir = "failed"
+)
+
+// inboxFiles lists the files waiting in the inbox; hidden, .tmp and .part files are skipped so that writers can rename them in place when complete
+func inboxFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read inbox directory")
+	}
+
+	var res []string
+
+	for _, e := range entries {
+		name := e.Name()
+
+		if !e.Type().IsRegular() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".tmp") || strings.HasSuffix(name, ".part") {
+			continue
+		}
+
+		res = append(res, filepath.Join(dir, name))
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// moveInboxFile moves the processed file to the subdirectory without overwriting earlier files of the same name
+func moveInboxFile(p string, sub string) (string, error) {
+	dir := filepath.Join(filepath.Dir(p), sub)
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create inbox subdirectory")
+	}
+
+	target := filepath.Join(dir, filepath.Base(p))
+	if _, err := os.Stat(target); err == nil {
+		target = fmt.Sprintf("%s.%d", target, time.Now().UnixNano())
+	}
+
+	err = os.Rename(p, target)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to move inbox file")
+	}
+
+	return target, nil
+}
+
+func (r *FsRunner) inboxOutput(p string) string {
+	if len(r.out) == 0 {
+		return ""
+	}
+
+	if r.outMode == FsOutputSplit {
+		return r.out
+	}
+
+	name := filepath.Base(p)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	return filepath.Join(r.out, name+".stxn")
+}
+
+// processInbox processes every file currently waiting in the inbox
+func (r *FsRunner) processInbox() error {
+	paths, err := inboxFiles(r.inbox)
+	if err != nil {
+		return err
+	}
+
+	if len(paths) > 0 && len(r.out) > 0 {
+		err = os.MkdirAll(r.out, 0755)
+		if err != nil {
+			return errors.Wrap(err, "failed to create outbox directory")
+		}
+	}
+
+	for _, p := range paths {
+		fmt.Println("Inbox file:", p)
+
+		perr := r.process([]string{p}, r.inboxOutput(p))
+
+		if perr == nil {
+			target, err := moveInboxFile(p, inboxDoneDir)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("Done:", target)
+			continue
+		}
+
+		fmt.Println("Failed:", p, "error:", perr)
+
+		target, err := moveInboxFile(p, inboxFailedDir)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(target+".error", []byte(perr.Error()+"\n"), 0644)
+		if err != nil {
+			return errors.Wrap(err, "failed to write error file")
+		}
+	}
+
+	return nil
+}
+
+func (r *FsRunner) runInbox() error {
+	fmt.Println("Watching inbox:", r.inbox)
+
+	for {
+		err := r.processInbox()
+		if err != nil {
+			return err
+		}
+
+		time.Sleep(r.poll)
+	}
+}
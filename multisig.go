@@ -85,3 +85,288 @@ func ConvertToMultisig(bs []byte, ma crypto.MultisigAccount) ([]byte, error) {
 
 	return mstx, nil
 }
+
+// MergePartials merges partially signed copies of the same transactions coming from different cosigners.
+// Each of the partials holds the whole group; single signatures are converted to multisig using ma or,
+// if ma is nil, the multisig account found in the other partials. Transactions that are not signed by
+// the multisig account, e.g. a counterparty's signed transaction, are passed through unchanged.
+func MergePartials(partials [][]types.SignedTxn, ma *crypto.MultisigAccount) ([]types.SignedTxn, error) {
+	if len(partials) == 0 {
+		return nil, errors.New("no partial transactions")
+	}
+
+	count := len(partials[0])
+
+	for i, p := range partials {
+		if len(p) != count {
+			return nil, errors.Errorf("invalid number of partial transactions - got: %d, expected: %d, index: %d", len(p), count, i)
+		}
+	}
+
+	res := make([]types.SignedTxn, count)
+
+	for i := 0; i < count; i++ {
+		stxs := make([]types.SignedTxn, len(partials))
+
+		txid := crypto.TransactionIDString(partials[0][i].Txn)
+
+		for j, p := range partials {
+			if crypto.TransactionIDString(p[i].Txn) != txid {
+				return nil, errors.Errorf("partial transactions differ - index: %d, partial: %d", i, j)
+			}
+
+			stxs[j] = p[i]
+		}
+
+		txma, err := partialsMultisig(stxs, ma)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get multisig account - index: %d", i)
+		}
+
+		if txma == nil {
+			stx, err := passPartials(stxs)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to pass through transaction - index: %d", i)
+			}
+
+			res[i] = stx
+			continue
+		}
+
+		stx, err := mergeMultisigPartials(stxs, *txma)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to merge multisig transactions - index: %d", i)
+		}
+
+		res[i] = stx
+	}
+
+	return res, nil
+}
+
+// partialsMultisig returns the multisig account that signs the transaction or nil if it is signed by another account
+func partialsMultisig(stxs []types.SignedTxn, ma *crypto.MultisigAccount) (*crypto.MultisigAccount, error) {
+	for _, stx := range stxs {
+		if stx.Msig.Blank() {
+			continue
+		}
+
+		pma, err := crypto.MultisigAccountFromSig(stx.Msig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get multisig account from signature")
+		}
+
+		return &pma, nil
+	}
+
+	if ma == nil {
+		return nil, nil
+	}
+
+	maddr, err := ma.Address()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multisig address")
+	}
+
+	for _, stx := range stxs {
+		if stx.Txn.Sender == maddr || stx.AuthAddr == maddr {
+			return ma, nil
+		}
+
+		// a member signature of an account rekeyed to the multisig
+		if stx.Sig != (types.Signature{}) && !stx.AuthAddr.IsZero() && isMultisigMember(*ma, stx.AuthAddr) {
+			return ma, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func isMultisigMember(ma crypto.MultisigAccount, addr types.Address) bool {
+	for _, pk := range ma.Pks {
+		if bytes.Equal(pk, addr[:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// passPartials returns the signed copy of a transaction that is not signed by the multisig account
+func passPartials(stxs []types.SignedTxn) (types.SignedTxn, error) {
+	var res *types.SignedTxn
+
+	for j := range stxs {
+		stx := stxs[j]
+
+		if !IsSigned(stx) {
+			continue
+		}
+
+		if res == nil {
+			res = &stx
+			continue
+		}
+
+		if !bytes.Equal(msgpack.Encode(*res), msgpack.Encode(stx)) {
+			return types.SignedTxn{}, errors.Errorf("conflicting signatures - partial: %d", j)
+		}
+	}
+
+	if res == nil {
+		return stxs[0], nil
+	}
+
+	return *res, nil
+}
+
+func mergeMultisigPartials(stxs []types.SignedTxn, ma crypto.MultisigAccount) (types.SignedTxn, error) {
+	maddr, err := ma.Address()
+	if err != nil {
+		return types.SignedTxn{}, errors.Wrap(err, "failed to get multisig address")
+	}
+
+	var mstxs [][]byte
+
+	for j, stx := range stxs {
+		if !IsSigned(stx) {
+			continue
+		}
+
+		if !stx.Lsig.Blank() {
+			return types.SignedTxn{}, errors.Errorf("unexpected logic signature - partial: %d", j)
+		}
+
+		mbs, err := ConvertToMultisig(msgpack.Encode(stx), ma)
+		if err != nil {
+			return types.SignedTxn{}, errors.Wrapf(err, "failed to convert to multisig - partial: %d", j)
+		}
+
+		var mstx types.SignedTxn
+		err = msgpack.Decode(mbs, &mstx)
+		if err != nil {
+			return types.SignedTxn{}, errors.Wrap(err, "failed to decode multisig transaction msgpack")
+		}
+
+		// the auth address has to match between the partials to be merged
+		if mstx.Txn.Sender == maddr {
+			mstx.AuthAddr = types.ZeroAddress
+		} else {
+			mstx.AuthAddr = maddr
+		}
+
+		mstxs = append(mstxs, msgpack.Encode(mstx))
+	}
+
+	var merged []byte
+
+	switch len(mstxs) {
+	case 0:
+		return stxs[0], nil
+	case 1:
+		merged = mstxs[0]
+	default:
+		_, merged, err = crypto.MergeMultisigTransactions(mstxs...)
+		if err != nil {
+			return types.SignedTxn{}, errors.Wrap(err, "failed to merge multisig transactions")
+		}
+	}
+
+	var res types.SignedTxn
+	err = msgpack.Decode(merged, &res)
+	if err != nil {
+		return types.SignedTxn{}, errors.Wrap(err, "failed to decode merged transaction msgpack")
+	}
+
+	return res, nil
+}
+
+// VerifySig checks the single signature of the transaction against its sender or auth address
+func VerifySig(stx types.SignedTxn) bool {
+	if stx.Sig == (types.Signature{}) {
+		return false
+	}
+
+	signer := stx.Txn.Sender
+	if !stx.AuthAddr.IsZero() {
+		signer = stx.AuthAddr
+	}
+
+	msg := append([]byte("TX"), msgpack.Encode(stx.Txn)...)
+
+	return ed25519.Verify(signer[:], msg, stx.Sig[:])
+}
+
+type MultisigSubsigStatus struct {
+	Address string `json:"address"`
+	Signed  bool   `json:"signed"`
+	Valid   bool   `json:"valid"`
+}
+
+type MultisigStatus struct {
+	TxID      string                 `json:"txid"`
+	Address   string                 `json:"address"`
+	Version   uint8                  `json:"version"`
+	Threshold uint8                  `json:"threshold"`
+	Subsigs   []MultisigSubsigStatus `json:"subsigs"`
+	Valid     int                    `json:"valid"`
+	Invalid   int                    `json:"invalid"`
+	Missing   int                    `json:"missing"`
+	Ready     bool                   `json:"ready"`
+}
+
+// GetMultisigStatus verifies the subsigs present in a multisig transaction and counts the missing ones
+func GetMultisigStatus(stx types.SignedTxn) (*MultisigStatus, error) {
+	if stx.Msig.Blank() {
+		return nil, errors.New("not a multisig transaction")
+	}
+
+	ma, err := crypto.MultisigAccountFromSig(stx.Msig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multisig account from signature")
+	}
+
+	maddr, err := ma.Address()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multisig address")
+	}
+
+	status := &MultisigStatus{
+		TxID:      crypto.TransactionIDString(stx.Txn),
+		Address:   maddr.String(),
+		Version:   stx.Msig.Version,
+		Threshold: stx.Msig.Threshold,
+	}
+
+	msg := append([]byte("TX"), msgpack.Encode(stx.Txn)...)
+
+	for _, ss := range stx.Msig.Subsigs {
+		var addr types.Address
+		copy(addr[:], ss.Key)
+
+		sst := MultisigSubsigStatus{
+			Address: addr.String(),
+			Signed:  ss.Sig != (types.Signature{}),
+		}
+
+		if sst.Signed {
+			sst.Valid = ed25519.Verify(ss.Key, msg, ss.Sig[:])
+			if sst.Valid {
+				status.Valid++
+			} else {
+				status.Invalid++
+			}
+		}
+
+		status.Subsigs = append(status.Subsigs, sst)
+	}
+
+	if status.Valid < int(status.Threshold) {
+		status.Missing = int(status.Threshold) - status.Valid
+	}
+
+	// a single invalid subsig makes the whole multisig invalid
+	status.Ready = status.Missing == 0 && status.Invalid == 0
+
+	return status, nil
+}
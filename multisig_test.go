@@ -111,3 +111,133 @@ func TestAsastatsGardRegression(t *testing.T) {
 
 	assert.Equal(t, "BQHE7KAK34WIXOM2TK7IFAU7YTAU3XRMHAH666ON4UUGDQGYJ4TQ", id)
 }
+
+func TestMergePartialsAndStatus(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	acc3 := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address, acc3.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 123, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	_, mstx1, err := crypto.SignMultisigTransaction(acc1.PrivateKey, ma, tx)
+	assert.NoError(t, err)
+	_, stx3, err := crypto.SignTransaction(acc3.PrivateKey, tx)
+	assert.NoError(t, err)
+
+	var p1, p3 types.SignedTxn
+	assert.NoError(t, msgpack.Decode(mstx1, &p1))
+	assert.NoError(t, msgpack.Decode(stx3, &p3))
+
+	status, err := GetMultisigStatus(p1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Valid)
+	assert.Equal(t, 1, status.Missing)
+	assert.False(t, status.Ready)
+
+	merged, err := MergePartials([][]types.SignedTxn{{p1}, {p3}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, merged, 1)
+
+	status, err = GetMultisigStatus(merged[0])
+	assert.NoError(t, err)
+	assert.Equal(t, maddr.String(), status.Address)
+	assert.Equal(t, 2, status.Valid)
+	assert.Equal(t, 0, status.Missing)
+	assert.True(t, status.Ready)
+	assert.True(t, status.Subsigs[0].Valid)
+	assert.False(t, status.Subsigs[1].Signed)
+	assert.True(t, status.Subsigs[2].Valid)
+}
+
+func TestMultisigStatusInvalidSubsig(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 1, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 123, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	_, mstx, err := crypto.SignMultisigTransaction(acc1.PrivateKey, ma, tx)
+	assert.NoError(t, err)
+
+	var stx types.SignedTxn
+	assert.NoError(t, msgpack.Decode(mstx, &stx))
+
+	stx.Msig.Subsigs[0].Sig[0] ^= 0xff
+
+	status, err := GetMultisigStatus(stx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Invalid)
+	assert.False(t, status.Ready)
+}
+
+func TestMergePartialsMixedGroup(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx1, err := transaction.MakePaymentTxn(maddr.String(), other.Address.String(), 1000, 123, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx2, err := transaction.MakePaymentTxn(other.Address.String(), maddr.String(), 1000, 123, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	txs, err := transaction.AssignGroupID([]types.Transaction{tx1, tx2}, "")
+	assert.NoError(t, err)
+
+	_, mbs1, err := crypto.SignMultisigTransaction(acc1.PrivateKey, ma, txs[0])
+	assert.NoError(t, err)
+	_, bs2, err := crypto.SignTransaction(acc2.PrivateKey, txs[0])
+	assert.NoError(t, err)
+	_, obs, err := crypto.SignTransaction(other.PrivateKey, txs[1])
+	assert.NoError(t, err)
+
+	var m1, s2, o types.SignedTxn
+	assert.NoError(t, msgpack.Decode(mbs1, &m1))
+	assert.NoError(t, msgpack.Decode(bs2, &s2))
+	assert.NoError(t, msgpack.Decode(obs, &o))
+
+	partials := [][]types.SignedTxn{
+		{m1, o},
+		{s2, {Txn: txs[1]}},
+	}
+
+	for _, pma := range []*crypto.MultisigAccount{nil, &ma} {
+		merged, err := MergePartials(partials, pma)
+		assert.NoError(t, err)
+		assert.Len(t, merged, 2)
+
+		status, err := GetMultisigStatus(merged[0])
+		assert.NoError(t, err)
+		assert.True(t, status.Ready)
+
+		assert.Equal(t, o, merged[1])
+		assert.True(t, VerifySig(merged[1]))
+	}
+
+	// the partials have to hold the same transactions
+	partials[1][1] = types.SignedTxn{Txn: txs[0]}
+
+	_, err = MergePartials(partials, nil)
+	assert.Error(t, err)
+
+	o.Sig[0] ^= 0xff
+	assert.False(t, VerifySig(o))
+}
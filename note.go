@@ -0,0 +1,246 @@
+package ams
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+)
+
+type NoteFormat string
+
+const (
+	NoteFormatJSON    NoteFormat = "json"
+	NoteFormatMsgpack NoteFormat = "msgpack"
+	NoteFormatText    NoteFormat = "text"
+	NoteFormatBinary  NoteFormat = "binary"
+)
+
+// arc2Prefix matches the ARC-2 "<dapp-name>:<data-format>" note prefix
+var arc2Prefix = regexp.MustCompile(`^([a-zA-Z0-9][a-zA-Z0-9_/@.-]{4,31}):([mjbu])`)
+
+// escapeSequence matches the CSI, OSC and the two character terminal escape sequences
+var escapeSequence = regexp.MustCompile("\x1b(\\[[0-?]*[ -/]*[@-~]|\\][^\x07\x1b]*(\x07|\x1b\\\\)?|[@-Z\\\\-_])")
+
+// DecodedNote is the terminal safe rendering of a transaction note
+type DecodedNote struct {
+	// Dapp is the ARC-2 dapp name of the note
+	Dapp   string     `json:"dapp,omitempty" yaml:"dapp,omitempty"`
+	Format NoteFormat `json:"format" yaml:"format"`
+	Text   string     `json:"text" yaml:"text"`
+	// Sanitized is set when escape sequences or control characters were removed from the text
+	Sanitized bool `json:"sanitized,omitempty" yaml:"sanitized,omitempty"`
+}
+
+// SanitizeText removes terminal escape sequences, control characters other than newlines and tabs
+// and bidirectional text overrides; the result reports whether anything was removed
+func SanitizeText(s string) (string, bool) {
+	clean := strings.ToValidUTF8(s, "\uFFFD")
+	clean = escapeSequence.ReplaceAllString(clean, "")
+
+	clean = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r), unicode.Is(unicode.Bidi_Control, r):
+			return -1
+		}
+
+		return r
+	}, clean)
+
+	return clean, clean != s
+}
+
+// isPrintableText reports whether the data is text once the escape sequences and control characters are removed
+func isPrintableText(bs []byte) bool {
+	if !utf8.Valid(bs) || bytes.IndexByte(bs, 0) >= 0 {
+		return false
+	}
+
+	clean, _ := SanitizeText(string(bs))
+	if len(clean) == 0 {
+		return false
+	}
+
+	for _, r := range clean {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// msgpackToJSON converts decoded msgpack values into values that encoding/json accepts
+func msgpackToJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			key := fmt.Sprint(k)
+			if bs, ok := k.([]byte); ok {
+				key = string(bs)
+			}
+
+			m[key] = msgpackToJSON(v)
+		}
+
+		return m
+	case []interface{}:
+		res := make([]interface{}, len(t))
+		for i, v := range t {
+			res[i] = msgpackToJSON(v)
+		}
+
+		return res
+	case []byte:
+		if utf8.Valid(t) {
+			return string(t)
+		}
+
+		return base64.StdEncoding.EncodeToString(t)
+	}
+
+	return v
+}
+
+func decodeNoteMsgpack(data []byte) (string, bool) {
+	if len(data) == 0 {
+		return "", false
+	}
+
+	var v interface{}
+
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	err := dec.Decode(&v)
+	if err != nil || dec.NumBytesRead() != len(data) {
+		return "", false
+	}
+
+	bs, err := json.MarshalIndent(msgpackToJSON(v), "", "  ")
+	if err != nil {
+		return "", false
+	}
+
+	return string(bs), true
+}
+
+func decodeNoteJSON(data []byte) (string, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return "", false
+	}
+
+	var out bytes.Buffer
+	err := json.Indent(&out, trimmed, "", "  ")
+	if err != nil {
+		return "", false
+	}
+
+	return out.String(), true
+}
+
+// isMsgpackContainer reports whether the data starts with a msgpack map or array
+func isMsgpackContainer(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	b := data[0]
+	return (b >= 0x80 && b <= 0x9f) || (b >= 0xdc && b <= 0xdf)
+}
+
+func formatBinaryNote(data []byte) string {
+	return fmt.Sprintf("0x%s (base64: %s)", hex.EncodeToString(data), base64.StdEncoding.EncodeToString(data))
+}
+
+// DecodeNote recognizes ARC-2 prefixed notes and renders json, msgpack and utf-8 notes;
+// other notes are shown as hex and base64
+func DecodeNote(note []byte) DecodedNote {
+	var n DecodedNote
+
+	data := note
+	hint := byte(0)
+
+	if m := arc2Prefix.FindSubmatch(note); m != nil {
+		n.Dapp = string(m[1])
+		hint = m[2][0]
+		data = note[len(m[0]):]
+	}
+
+	var text string
+	var ok bool
+
+	switch hint {
+	case 'j':
+		text, ok = decodeNoteJSON(data)
+		n.Format = NoteFormatJSON
+	case 'm':
+		text, ok = decodeNoteMsgpack(data)
+		n.Format = NoteFormatMsgpack
+	case 'b':
+		text, ok = formatBinaryNote(data), true
+		n.Format = NoteFormatBinary
+	case 'u':
+		text, ok = string(data), utf8.Valid(data)
+		n.Format = NoteFormatText
+	}
+
+	if !ok {
+		switch {
+		case isMsgpackContainer(data):
+			text, ok = decodeNoteMsgpack(data)
+			n.Format = NoteFormatMsgpack
+		case isPrintableText(data):
+			text, ok = decodeNoteJSON(data)
+			n.Format = NoteFormatJSON
+		}
+	}
+
+	if !ok {
+		if isPrintableText(data) {
+			text = string(data)
+			n.Format = NoteFormatText
+		} else {
+			text = formatBinaryNote(data)
+			n.Format = NoteFormatBinary
+		}
+	}
+
+	n.Text, n.Sanitized = SanitizeText(text)
+
+	return n
+}
+
+// String renders the note indenting multi-line text so that it cannot pass for other output
+func (n DecodedNote) String() string {
+	var qualifiers []string
+
+	if len(n.Dapp) > 0 {
+		qualifiers = append(qualifiers, n.Dapp)
+	}
+
+	if n.Format != NoteFormatText {
+		qualifiers = append(qualifiers, string(n.Format))
+	}
+
+	header := "Note"
+	if len(qualifiers) > 0 {
+		header = fmt.Sprintf("Note (%s)", strings.Join(qualifiers, ", "))
+	}
+
+	if !strings.Contains(n.Text, "\n") {
+		return fmt.Sprintf("%s: %s", header, n.Text)
+	}
+
+	lines := strings.Split(n.Text, "\n")
+	return fmt.Sprintf("%s:\n  %s", header, strings.Join(lines, "\n  "))
+}
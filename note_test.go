@@ -0,0 +1,45 @@
+package ams
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeNote(t *testing.T) {
+	n := DecodeNote([]byte(`mydapp:j{"a":1,"b":[true]}`))
+	assert.Equal(t, "mydapp", n.Dapp)
+	assert.Equal(t, NoteFormatJSON, n.Format)
+	assert.Equal(t, "{\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}", n.Text)
+
+	n = DecodeNote(msgpack.Encode(map[string]interface{}{"k": "v"}))
+	assert.Equal(t, NoteFormatMsgpack, n.Format)
+	assert.Equal(t, "{\n  \"k\": \"v\"\n}", n.Text)
+
+	n = DecodeNote([]byte("mydapp:uhello"))
+	assert.Equal(t, NoteFormatText, n.Format)
+	assert.Equal(t, "Note (mydapp): hello", n.String())
+
+	n = DecodeNote([]byte{0x00, 0xff})
+	assert.Equal(t, NoteFormatBinary, n.Format)
+	assert.Equal(t, "Note (binary): 0x00ff (base64: AP8=)", n.String())
+
+	n = DecodeNote([]byte("pay \x1b[2J\x1b]0;title\x07ok‮"))
+	assert.Equal(t, NoteFormatText, n.Format)
+	assert.Equal(t, "pay ok", n.Text)
+	assert.True(t, n.Sanitized)
+
+	n = DecodeNote([]byte("line 1\nPress Enter to sign"))
+	assert.Equal(t, "Note:\n  line 1\n  Press Enter to sign", n.String())
+}
+
+func TestSanitizeText(t *testing.T) {
+	s, changed := SanitizeText("USDC")
+	assert.Equal(t, "USDC", s)
+	assert.False(t, changed)
+
+	s, changed = SanitizeText("US\rDC\x1b[31m")
+	assert.Equal(t, "USDC", s)
+	assert.True(t, changed)
+}
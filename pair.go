@@ -0,0 +1,424 @@
+package ams
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/wc"
+	"github.com/pkg/errors"
+)
+
+type Pairer struct {
+	members  []types.Address
+	need     int
+	mas      []crypto.MultisigAccount
+	timeout  time.Duration
+	maxTries int
+
+	meta wc.SessionRequestPeerMeta
+	uh   func(wc.Uri) error
+
+	book *AddressBook
+
+	// connect opens a single wallet session
+	connect func(ctx context.Context) (*pairSession, error)
+
+	debug bool
+}
+
+type PairerOption func(p *Pairer)
+
+func WithPairerMembers(members []types.Address) PairerOption {
+	return func(p *Pairer) {
+		p.members = members
+	}
+}
+
+func WithPairerThreshold(need int) PairerOption {
+	return func(p *Pairer) {
+		p.need = need
+	}
+}
+
+// WithPairerMultisigs makes the pairing last until every multisig account has enough members paired
+func WithPairerMultisigs(mas []crypto.MultisigAccount) PairerOption {
+	return func(p *Pairer) {
+		p.mas = append(p.mas, mas...)
+	}
+}
+
+// WithPairerTimeout bounds the whole pairing; zero means no limit
+func WithPairerTimeout(timeout time.Duration) PairerOption {
+	return func(p *Pairer) {
+		p.timeout = timeout
+	}
+}
+
+// WithPairerMaxTries limits the number of wallet sessions attempted; zero means no limit
+func WithPairerMaxTries(tries int) PairerOption {
+	return func(p *Pairer) {
+		p.maxTries = tries
+	}
+}
+
+func WithPairerPeerMeta(meta wc.SessionRequestPeerMeta) PairerOption {
+	return func(p *Pairer) {
+		p.meta = meta
+	}
+}
+
+func WithPairerUrlHandler(handler func(wc.Uri) error) PairerOption {
+	return func(p *Pairer) {
+		p.uh = handler
+	}
+}
+
+// WithPairerAddressBook shows the address book labels of the paired signers
+func WithPairerAddressBook(b *AddressBook) PairerOption {
+	return func(p *Pairer) {
+		p.book = b
+	}
+}
+
+func WithPairerDebug(debug bool) PairerOption {
+	return func(p *Pairer) {
+		p.debug = debug
+	}
+}
+
+func MakePairer(opts ...PairerOption) (*Pairer, error) {
+	p := &Pairer{
+		need: 1,
+	}
+
+	p.connect = p.connectWallet
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.need < 1 {
+		return nil, errors.New("threshold must be > 0")
+	}
+
+	if len(p.members) > 0 && len(p.members) < p.need {
+		return nil, errors.New("number of members is less than the threshold")
+	}
+
+	return p, nil
+}
+
+type pairSession struct {
+	conn   *wc.Conn
+	peer   *wc.Client
+	res    *wc.SessionRequestResponseResult
+	closed bool
+}
+
+type sessionUpdateParams struct {
+	Approved  bool     `json:"approved"`
+	ChainId   int      `json:"chainId"`
+	NetworkId int      `json:"networkId"`
+	Accounts  []string `json:"accounts"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// Reject ends the wallet session with a wc_sessionUpdate that is not approved and closes the connection
+func (s *pairSession) Reject(message string) error {
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+
+	if s.conn == nil {
+		return nil
+	}
+
+	defer s.conn.Close()
+
+	id := uint64(time.Now().UnixNano())
+
+	req := wc.Request{
+		Header: wc.MakeRequestHeader(id, "wc_sessionUpdate"),
+		Params: []interface{}{
+			sessionUpdateParams{
+				Approved: false,
+				Message:  message,
+			},
+		},
+	}
+
+	err := s.conn.Send(s.res.PeerId, req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send session update")
+	}
+
+	return nil
+}
+
+type pairAttempt struct {
+	s   *pairSession
+	err error
+}
+
+func (p *Pairer) connectWallet(ctx context.Context) (*pairSession, error) {
+	ch := make(chan pairAttempt, 1)
+
+	go func() {
+		conn, err := wc.MakeConn(
+			wc.WithConnDebug(p.debug),
+		)
+		if err != nil {
+			ch <- pairAttempt{err: errors.Wrap(err, "failed to make connection")}
+			return
+		}
+
+		// the dial cannot be cancelled so the connection is closed once pairing gave up on it
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+			case <-done:
+			}
+		}()
+
+		peer, res, err := wc.MakeClient(
+			p.meta,
+			wc.WithClientDebug(p.debug),
+			wc.WithClientConn(conn),
+			wc.WithClientUrlHandler(func(uri wc.Uri) error {
+				if p.uh != nil {
+					return p.uh(uri)
+				}
+				return nil
+			}))
+
+		close(done)
+
+		if err != nil {
+			conn.Close()
+			ch <- pairAttempt{err: errors.Wrap(err, "failed to make client")}
+			return
+		}
+
+		s := &pairSession{
+			conn: conn,
+			peer: peer,
+			res:  res,
+		}
+
+		select {
+		case <-ctx.Done():
+			// the wallet connected after pairing gave up on it
+			s.Reject("Pairing timed out")
+		case ch <- pairAttempt{s: s}:
+		}
+	}()
+
+	select {
+	case a := <-ch:
+		return a.s, a.err
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "pairing timed out")
+	}
+}
+
+// pairRequirement is a set of members out of which at least need have to be paired
+type pairRequirement struct {
+	members map[string]bool
+	need    int
+}
+
+func (p *Pairer) requirements() []pairRequirement {
+	var reqs []pairRequirement
+
+	if len(p.members) > 0 {
+		r := pairRequirement{
+			members: map[string]bool{},
+			need:    p.need,
+		}
+
+		for _, m := range p.members {
+			r.members[m.String()] = true
+		}
+
+		reqs = append(reqs, r)
+	}
+
+	for _, ma := range p.mas {
+		r := pairRequirement{
+			members: map[string]bool{},
+			need:    int(ma.Threshold),
+		}
+
+		for _, pk := range ma.Pks {
+			var addr types.Address
+			copy(addr[:], pk)
+			r.members[addr.String()] = true
+		}
+
+		reqs = append(reqs, r)
+	}
+
+	return reqs
+}
+
+// missingSigners returns the number of members that still have to be paired to satisfy all the requirements
+func missingSigners(reqs []pairRequirement, paired map[string]bool) int {
+	var missing int
+
+	for _, r := range reqs {
+		var got int
+		for m := range r.members {
+			if paired[m] {
+				got++
+			}
+		}
+
+		if got < r.need {
+			missing += r.need - got
+		}
+	}
+
+	return missing
+}
+
+// classifyAccounts splits wallet accounts into the ones that are accepted as new members and the ones already paired
+func classifyAccounts(accounts []string, left map[string]bool, paired map[string]bool) (accepted []string, dups []string) {
+	for _, addr := range accounts {
+		if left[addr] {
+			accepted = append(accepted, addr)
+			delete(left, addr)
+		} else if paired[addr] {
+			dups = append(dups, addr)
+		}
+	}
+
+	return
+}
+
+func (p *Pairer) reject(s *pairSession, msg string) {
+	fmt.Println("Rejected wallet:", msg)
+
+	err := s.Reject(msg)
+	if err != nil {
+		fmt.Println("Failed to reject wallet:", err)
+	}
+}
+
+func (p *Pairer) Pair() ([]PeerAddr, error) {
+	ctx := context.Background()
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	reqs := p.requirements()
+
+	left := map[string]bool{}
+	for _, r := range reqs {
+		for m := range r.members {
+			left[m] = true
+		}
+	}
+
+	paired := map[string]bool{}
+
+	var pa []PeerAddr
+	var sessions []*pairSession
+	var tries int
+
+	fail := func(err error) ([]PeerAddr, error) {
+		for _, s := range sessions {
+			p.reject(s, "Pairing failed")
+		}
+
+		return nil, err
+	}
+
+	missing := func() int {
+		if len(reqs) == 0 {
+			return p.need - len(pa)
+		}
+
+		return missingSigners(reqs, paired)
+	}
+
+	for missing() > 0 {
+		if p.maxTries > 0 && tries >= p.maxTries {
+			return fail(errors.Errorf("failed to pair enough signers - got: %d, missing: %d, tries: %d", len(pa), missing(), tries))
+		}
+
+		fmt.Printf("Signers - missing: %d, got: %d, tries: %d:\n", missing(), len(pa), tries)
+
+		tries++
+
+		s, err := p.connect(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fail(errors.Wrapf(err, "failed to pair enough signers - got: %d, missing: %d", len(pa), missing()))
+			}
+
+			fmt.Println("Pairing failed:", err)
+			continue
+		}
+
+		if len(reqs) == 0 {
+			if len(s.res.Accounts) == 0 {
+				p.reject(s, "No accounts")
+				continue
+			}
+
+			addr := s.res.Accounts[0]
+			sessions = append(sessions, s)
+			paired[addr] = true
+			pa = append(pa, PeerAddr{
+				Peer:    s.peer,
+				Address: addr,
+			})
+
+			fmt.Println("Paired signer:", p.book.Format(addr))
+			continue
+		}
+
+		accepted, dups := classifyAccounts(s.res.Accounts, left, paired)
+
+		for _, addr := range dups {
+			fmt.Println("Ignored already paired signer:", p.book.Format(addr))
+		}
+
+		if len(accepted) == 0 {
+			var msg string
+			if len(dups) > 0 {
+				msg = fmt.Sprintf("Already paired: %s", strings.Join(dups, ", "))
+			} else {
+				msg = "None of the wallet accounts is a multisig member"
+			}
+
+			p.reject(s, msg)
+			continue
+		}
+
+		sessions = append(sessions, s)
+
+		for _, addr := range accepted {
+			paired[addr] = true
+			pa = append(pa, PeerAddr{
+				Peer:    s.peer,
+				Address: addr,
+			})
+
+			fmt.Println("Paired signer:", p.book.Format(addr))
+		}
+	}
+
+	return pa, nil
+}
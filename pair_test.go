@@ -0,0 +1,129 @@
+package ams
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/wc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyAccounts(t *testing.T) {
+	left := map[string]bool{
+		"A": true,
+		"B": true,
+	}
+
+	paired := map[string]bool{
+		"C": true,
+	}
+
+	accepted, dups := classifyAccounts([]string{"A", "C", "D", "B"}, left, paired)
+
+	assert.Equal(t, []string{"A", "B"}, accepted)
+	assert.Equal(t, []string{"C"}, dups)
+	assert.Len(t, left, 0)
+}
+
+func TestClassifyAccountsNonMember(t *testing.T) {
+	left := map[string]bool{
+		"A": true,
+	}
+
+	accepted, dups := classifyAccounts([]string{"X", "Y"}, left, map[string]bool{})
+
+	assert.Len(t, accepted, 0)
+	assert.Len(t, dups, 0)
+	assert.True(t, left["A"])
+}
+
+func TestMissingSigners(t *testing.T) {
+	reqs := []pairRequirement{
+		{
+			members: map[string]bool{"A": true, "B": true, "C": true},
+			need:    2,
+		},
+		{
+			members: map[string]bool{"B": true, "D": true},
+			need:    2,
+		},
+	}
+
+	assert.Equal(t, 4, missingSigners(reqs, map[string]bool{}))
+	assert.Equal(t, 2, missingSigners(reqs, map[string]bool{"B": true}))
+	assert.Equal(t, 1, missingSigners(reqs, map[string]bool{"A": true, "B": true}))
+	assert.Equal(t, 0, missingSigners(reqs, map[string]bool{"A": true, "B": true, "D": true}))
+}
+
+func TestPairTimeout(t *testing.T) {
+	p, err := MakePairer(WithPairerTimeout(50 * time.Millisecond))
+	assert.NoError(t, err)
+
+	p.connect = func(ctx context.Context) (*pairSession, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	_, err = p.Pair()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPairMaxTries(t *testing.T) {
+	p, err := MakePairer(WithPairerMaxTries(3))
+	assert.NoError(t, err)
+
+	var tries int
+	p.connect = func(ctx context.Context) (*pairSession, error) {
+		tries++
+		return nil, errors.New("wallet unavailable")
+	}
+
+	_, err = p.Pair()
+	assert.Error(t, err)
+	assert.Equal(t, 3, tries)
+}
+
+func TestPairRejectNonMember(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	p, err := MakePairer(
+		WithPairerMembers([]types.Address{acc1.Address, acc2.Address}),
+		WithPairerThreshold(2),
+		WithPairerMaxTries(3),
+	)
+	assert.NoError(t, err)
+
+	var sessions []*pairSession
+	accounts := [][]string{
+		{other.Address.String()},
+		{acc1.Address.String()},
+		{acc1.Address.String()},
+	}
+
+	p.connect = func(ctx context.Context) (*pairSession, error) {
+		s := &pairSession{
+			res: &wc.SessionRequestResponseResult{
+				Accounts: accounts[len(sessions)],
+			},
+		}
+
+		sessions = append(sessions, s)
+
+		return s, nil
+	}
+
+	_, err = p.Pair()
+	assert.Error(t, err)
+	assert.Len(t, sessions, 3)
+
+	// the non-member and the duplicate are rejected, the paired member is released on failure
+	for _, s := range sessions {
+		assert.True(t, s.closed)
+	}
+}
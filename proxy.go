@@ -1,11 +1,13 @@
 package ams
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
-	"sync"
+	"time"
 
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/crypto"
 	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
 	"github.com/algorand/go-algorand-sdk/types"
@@ -15,13 +17,22 @@ import (
 
 type ProxySigner struct {
 	pa   []PeerAddr
-	ma   *crypto.MultisigAccount
+	mas  []crypto.MultisigAccount
 	addr string
 
 	pcb   ProxyPeerAddrCallback
 	debug bool
+
+	// sign requests the signatures of a single peer
+	sign func(p PeerAddr, req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error)
+
+	ac      *algod.Client
+	sigTime time.Duration
 }
 
+// DefaultProxySignatureTime is the expected time a cosigner needs to review and sign a request
+const DefaultProxySignatureTime = 2 * time.Minute
+
 type ProxySignerOption func(s *ProxySigner)
 
 type ProxyPeerAddrCallback func() []PeerAddr
@@ -34,7 +45,30 @@ func WithProxySignerDebug(debug bool) ProxySignerOption {
 
 func WithProxySignerMultisig(ma *crypto.MultisigAccount) ProxySignerOption {
 	return func(s *ProxySigner) {
-		s.ma = ma
+		if ma != nil {
+			s.mas = append(s.mas, *ma)
+		}
+	}
+}
+
+// WithProxySignerMultisigs adds multisig accounts served by the proxy; transactions are routed to them by sender or auth address
+func WithProxySignerMultisigs(mas []crypto.MultisigAccount) ProxySignerOption {
+	return func(s *ProxySigner) {
+		s.mas = append(s.mas, mas...)
+	}
+}
+
+// WithProxySignerAlgod enables the warning about validity windows closing before the signatures are collected
+func WithProxySignerAlgod(ac *algod.Client) ProxySignerOption {
+	return func(s *ProxySigner) {
+		s.ac = ac
+	}
+}
+
+// WithProxySignerSignatureTime sets the expected time a cosigner needs to sign a request
+func WithProxySignerSignatureTime(d time.Duration) ProxySignerOption {
+	return func(s *ProxySigner) {
+		s.sigTime = d
 	}
 }
 
@@ -46,19 +80,23 @@ func WithProxySignerPeersCallback(cb ProxyPeerAddrCallback) ProxySignerOption {
 
 func MakeProxySigner(addr string, opts ...ProxySignerOption) (*ProxySigner, error) {
 	s := &ProxySigner{
-		addr: addr,
+		addr:    addr,
+		sigTime: DefaultProxySignatureTime,
+		sign:    PeerAddr.Sign,
 	}
 
 	for _, opt := range opts {
 		opt(s)
 	}
 
-	return s, nil
-}
+	for _, ma := range s.mas {
+		_, err := ma.Address()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get multisig address")
+		}
+	}
 
-type peerPartial struct {
-	Partial [][]byte
-	Address string
+	return s, nil
 }
 
 type PeerAddr struct {
@@ -70,163 +108,347 @@ func (pa PeerAddr) Sign(req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error) {
 	return pa.Peer.Sign(context.Background(), req)
 }
 
-func (s *ProxySigner) Sign(req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error) {
-	psch := make(chan peerPartial)
-	ctx, cancel := context.WithCancelCause(context.Background())
+// withAuthAddr returns a copy of the request with every transaction's auth address set to addr.
+// A single peer may hold several member addresses so the shared request must not be modified in place.
+func withAuthAddr(req wc.AlgoSignRequest, addr string) wc.AlgoSignRequest {
+	res := wc.AlgoSignRequest{
+		Params: make([][]wc.AlgoSignParams, len(req.Params)),
+	}
 
-	var wg sync.WaitGroup
-	pa := s.pcb()
+	for i, p := range req.Params {
+		res.Params[i] = make([]wc.AlgoSignParams, len(p))
+		copy(res.Params[i], p)
+	}
 
-	go func() {
-		for i, pu := range pa {
-			if s.debug {
-				fmt.Println("Peer sign request:", pu)
-			}
+	if len(res.Params) > 0 {
+		for i := range res.Params[0] {
+			res.Params[0][i].AuthAddr = addr
+		}
+	}
 
-			// TODO: make sequential processing optional
-			wg.Add(1)
+	return res
+}
 
-			go func(i int, p PeerAddr) {
-				err := func() error {
-					if s.debug {
-						fmt.Println("Requesting sign - peer:", p)
-					}
+// proxyRoute describes who has to sign a single transaction of the request
+type proxyRoute struct {
+	// ma is nil for transactions signed by a single address
+	ma   *crypto.MultisigAccount
+	addr types.Address
+	need int
 
-					for i := 0; i < len(req.Params[0]); i++ {
-						req.Params[0][i].AuthAddr = p.Address
-					}
+	// signed holds the members whose signatures are already present
+	signed map[string]bool
+}
 
-					resp, err := p.Sign(req)
-					if err != nil {
-						return errors.Wrapf(err, "failed to sign transactions - addr: %s, peer: %v", p.Address, p.Peer)
-					}
+func (r *proxyRoute) member(addr string) bool {
+	if r.signed[addr] {
+		return false
+	}
 
-					pstxs, err := wc.DecodeAlgoSignResponse(*resp)
-					if err != nil {
-						return errors.Wrap(err, "failed to decode sign response transactions")
-					}
+	a, err := types.DecodeAddress(addr)
+	if err != nil {
+		return false
+	}
 
-					select {
-					case psch <- peerPartial{
-						Partial: pstxs,
-						Address: p.Address,
-					}:
-					case <-ctx.Done():
-					}
+	if r.ma == nil {
+		return a == r.addr
+	}
 
-					return nil
-				}()
+	for _, pk := range r.ma.Pks {
+		if bytes.Equal(pk, a[:]) {
+			return true
+		}
+	}
 
-				if err != nil {
-					cancel(err)
-				}
-			}(i, pu)
+	return false
+}
 
-			wg.Wait()
+// convert turns a peer partial into the multisig form expected by the route
+func (r *proxyRoute) convert(bs []byte, peer string) ([]byte, error) {
+	if r.ma == nil {
+		return bs, nil
+	}
+
+	var stx types.SignedTxn
+	err := msgpack.Decode(bs, &stx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode signed transaction msgpack")
+	}
+
+	if stx.Msig.Blank() && stx.AuthAddr.IsZero() {
+		// some wallets do not set the signer when the auth address was requested
+		signer, err := types.DecodeAddress(peer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode peer address")
 		}
-	}()
 
-	var all []peerPartial
+		stx.AuthAddr = signer
+		bs = msgpack.Encode(stx)
+	}
 
-	var min int
-	if s.ma != nil {
-		min = int(s.ma.Threshold)
+	mbs, err := ConvertToMultisig(bs, *r.ma)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert to multisig")
 	}
-	if min == 0 {
-		min = 1
+
+	err = msgpack.Decode(mbs, &stx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode multisig transaction msgpack")
 	}
 
-	if s.debug {
-		fmt.Println("Awaiting responses:", min)
+	// the auth address has to match between the partials to be merged
+	if stx.Txn.Sender == r.addr {
+		stx.AuthAddr = types.ZeroAddress
+	} else {
+		stx.AuthAddr = r.addr
 	}
 
-	for i := 0; i < min; i++ {
-		// TODO: make this run as long as there aren't enough signed partials
-		// The loop here should handle sign errors and let the failed signer retry
-		// Additionally, could let other signers join too (needs changes to the Signer implementation)
-		select {
-		case pp := <-psch:
-			if s.debug {
-				fmt.Println("Received response - address:", pp.Address, ", len:", len(pp.Partial))
-			}
-			all = append(all, pp)
-			wg.Done()
-		case <-ctx.Done():
-			return nil, errors.Wrap(ctx.Err(), "Failed to sign transactions")
+	return msgpack.Encode(stx), nil
+}
+
+func (r *proxyRoute) merge(partials [][]byte) ([]byte, error) {
+	if len(partials) == 0 {
+		return nil, nil
+	}
+
+	if r.ma == nil || len(partials) == 1 {
+		return partials[0], nil
+	}
+
+	_, stx, err := crypto.MergeMultisigTransactions(partials...)
+	return stx, err
+}
+
+// routes resolves the signing route of every transaction of the request by its auth address or sender.
+// Transactions that do not belong to any served account or have an empty signers list get a nil route and are left unsigned.
+func (s *ProxySigner) routes(req wc.AlgoSignRequest, existing []types.SignedTxn) ([]*proxyRoute, error) {
+	if len(req.Params) == 0 {
+		return nil, nil
+	}
+
+	byAddr := map[types.Address]*proxyRoute{}
+
+	for i := range s.mas {
+		ma := &s.mas[i]
+
+		maddr, err := ma.Address()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get multisig address")
+		}
+
+		byAddr[maddr] = &proxyRoute{
+			ma:   ma,
+			addr: maddr,
+			need: int(ma.Threshold),
 		}
 	}
 
-	cancel(nil)
+	// a single served account signs everything it is asked for, e.g. when the sender is rekeyed to it
+	var fallback *proxyRoute
+
+	switch len(s.mas) {
+	case 0:
+		addr, err := types.DecodeAddress(s.addr)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode proxy address")
+		}
+
+		fallback = &proxyRoute{
+			addr: addr,
+			need: 1,
+		}
 
-	merge := func() func([][]byte) ([]byte, error) {
-		if s.ma != nil && s.ma.Threshold > 1 {
-			return func(txs [][]byte) ([]byte, error) {
-				_, stx, err := crypto.MergeMultisigTransactions(txs...)
-				return stx, err
+		byAddr[addr] = fallback
+	case 1:
+		for _, r := range byAddr {
+			fallback = r
+		}
+	}
+
+	p := req.Params[0]
+	res := make([]*proxyRoute, len(p))
+
+	for i, item := range p {
+		// an empty, but present, signers list asks the wallet not to sign the transaction
+		if item.Signers != nil && len(item.Signers) == 0 {
+			continue
+		}
+
+		bs, err := base64.StdEncoding.DecodeString(item.TxnBase64)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode base64 transaction data")
+		}
+
+		var txn types.Transaction
+		err = msgpack.Decode(bs, &txn)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode transaction msgpack")
+		}
+
+		signer := txn.Sender
+
+		if len(item.AuthAddr) > 0 {
+			signer, err = types.DecodeAddress(item.AuthAddr)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode auth address")
 			}
-		} else {
-			return func(txs [][]byte) ([]byte, error) {
-				if len(txs) == 0 {
-					return nil, nil
-				}
-				return txs[0], nil
+		}
+
+		r, ok := byAddr[signer]
+		if !ok {
+			r = fallback
+		}
+
+		res[i] = r
+	}
+
+	for i, stx := range existing {
+		if i >= len(res) || res[i] == nil {
+			continue
+		}
+
+		r := res[i]
+
+		if IsFullySigned(stx) {
+			res[i] = nil
+			continue
+		}
+
+		if r.ma == nil || stx.Msig.Blank() {
+			continue
+		}
+
+		// routes are shared between transactions so the partially signed ones get their own copy
+		pr := *r
+		pr.signed = map[string]bool{}
+
+		for _, ss := range stx.Msig.Subsigs {
+			if ss.Sig == (types.Signature{}) {
+				continue
 			}
+
+			var addr types.Address
+			copy(addr[:], ss.Key)
+
+			pr.signed[addr.String()] = true
+			pr.need--
 		}
-	}()
 
-	var count int
+		res[i] = &pr
+	}
+
+	return res, nil
+}
 
-	for _, pp := range all {
-		curr := len(pp.Partial)
-		if count < curr {
-			count = curr
+func (s *ProxySigner) Sign(req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error) {
+	return s.SignPartial(req, nil)
+}
+
+// SignPartial requests only the signatures that are missing from the existing transactions
+func (s *ProxySigner) SignPartial(req wc.AlgoSignRequest, existing []types.SignedTxn) (*wc.AlgoSignResponse, error) {
+	routes, err := s.routes(req, existing)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to route transactions")
+	}
+
+	// partials[i] holds the converted partial transactions received for the i-th transaction
+	partials := make([][][]byte, len(routes))
+
+	missing := func() int {
+		var count int
+		for i, r := range routes {
+			if r != nil && len(partials[i]) < r.need {
+				count++
+			}
 		}
+		return count
 	}
 
-	for _, pp := range all {
-		if len(pp.Partial) != count {
-			return nil, errors.Errorf("Received invalid number of partial transactions - got: %d, expected: %d, addr: %s", len(pp.Partial), count, pp.Address)
+	if s.debug {
+		fmt.Println("Awaiting signatures - transactions:", missing())
+	}
+
+	// remaining is the number of signatures still needed by the transaction that waits for the most of them
+	remaining := func() int {
+		var max int
+		for i, r := range routes {
+			if r != nil && r.need-len(partials[i]) > max {
+				max = r.need - len(partials[i])
+			}
 		}
+		return max
 	}
 
-	resp := wc.AlgoSignResponse{}
+	var clock *RoundClock
+	var txs []types.Transaction
 
-	for i := 0; i < count; i++ {
-		var partial [][]byte
+	if s.ac != nil {
+		txs, err = DecodeSignRequest(req)
+		if err != nil {
+			return nil, err
+		}
 
-		for _, pp := range all {
-			partial = append(partial, pp.Partial[i])
+		clock, err = FetchRoundClock(s.ac, DefaultBlockTimeSamples)
+		if err != nil {
+			fmt.Println("Failed to estimate validity window:", err)
 		}
+	}
 
-		if s.ma != nil {
-			maddr, err := s.ma.Address()
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to get multisig address")
+	pa := s.pcb()
+
+	// TODO: make sequential processing optional
+	for _, p := range pa {
+		if missing() == 0 {
+			break
+		}
+
+		var wanted bool
+		for i, r := range routes {
+			if r != nil && len(partials[i]) < r.need && r.member(p.Address) {
+				wanted = true
+				break
 			}
+		}
+
+		if !wanted {
+			continue
+		}
 
-			for i, bs := range partial {
-				var txn types.SignedTxn
-				err := msgpack.Decode(bs, &txn)
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to decode signed transaction msgpack")
-				}
-
-				if txn.AuthAddr.IsZero() {
-					// TODO: I'm not sure about it but needed for Exodus that does not set it
-					txn.AuthAddr = maddr
-
-					bs = msgpack.Encode(txn)
-				}
-
-				mstx, err := ConvertToMultisig(bs, *s.ma)
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to convert to multisig")
-				}
-				partial[i] = mstx
+		if clock != nil {
+			warnValidity(clock, txs, remaining(), s.sigTime, time.Now())
+		}
+
+		if s.debug {
+			fmt.Println("Requesting sign - peer:", p)
+		}
+
+		converted, err := s.signPeer(p, req, routes)
+		if err != nil {
+			// another member may still complete the threshold so the request goes on without this peer
+			fmt.Println("Failed to get signatures - addr:", p.Address, ", error:", err)
+			continue
+		}
+
+		for i, stx := range converted {
+			if stx != nil {
+				partials[i] = append(partials[i], stx)
 			}
 		}
+	}
 
-		stx, err := merge(partial)
+	if count := missing(); count > 0 {
+		return nil, errors.Errorf("Not enough signatures - transactions missing signatures: %d", count)
+	}
+
+	resp := wc.AlgoSignResponse{}
+
+	for i, r := range routes {
+		if r == nil {
+			resp.Result = append(resp.Result, "")
+			continue
+		}
+
+		stx, err := r.merge(partials[i])
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to merge partial transactions")
 		}
@@ -242,6 +464,97 @@ func (s *ProxySigner) Sign(req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error)
 	return &resp, nil
 }
 
+// signPeer requests the signatures of a single peer and returns its converted partial transactions by route index
+func (s *ProxySigner) signPeer(p PeerAddr, req wc.AlgoSignRequest, routes []*proxyRoute) ([][]byte, error) {
+	resp, err := s.sign(p, withAuthAddr(req, p.Address))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign transactions - addr: %s, peer: %v", p.Address, p.Peer)
+	}
+
+	pstxs, err := wc.DecodeAlgoSignResponse(*resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode sign response transactions")
+	}
+
+	if s.debug {
+		fmt.Println("Received response - address:", p.Address, ", len:", len(pstxs))
+	}
+
+	if len(pstxs) != len(routes) {
+		return nil, errors.Errorf("Received invalid number of partial transactions - got: %d, expected: %d, addr: %s", len(pstxs), len(routes), p.Address)
+	}
+
+	res := make([][]byte, len(routes))
+
+	for i, r := range routes {
+		if r == nil || len(pstxs[i]) == 0 || !r.member(p.Address) {
+			continue
+		}
+
+		stx, err := r.convert(pstxs[i], p.Address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert partial transaction - index: %d, addr: %s", i, p.Address)
+		}
+
+		res[i] = stx
+	}
+
+	return res, nil
+}
+
+// warnValidity prints a warning when the validity window closes before the remaining signatures are likely to arrive
+func warnValidity(c *RoundClock, txs []types.Transaction, remaining int, sigTime time.Duration, now time.Time) bool {
+	if len(txs) == 0 {
+		return false
+	}
+
+	// the group can be confirmed only while all of its transactions are valid
+	first, last := txs[0].FirstValid, txs[0].LastValid
+	for _, txn := range txs[1:] {
+		if txn.FirstValid > first {
+			first = txn.FirstValid
+		}
+
+		if txn.LastValid < last {
+			last = txn.LastValid
+		}
+	}
+
+	e := c.Estimate(uint64(first), uint64(last), now)
+
+	if e.Expired {
+		fmt.Printf("[!!!] Validity window closed at round %d - the transactions cannot be confirmed anymore\n", last)
+		return true
+	}
+
+	expected := time.Duration(remaining) * sigTime
+	if e.Remaining() < expected {
+		fmt.Printf("[!] Validity window closes in %s (round %d) but %d more signatures are likely to take %s\n",
+			formatApprox(e.Remaining()), last, remaining, formatApprox(expected))
+		return true
+	}
+
+	return false
+}
+
 func (s *ProxySigner) Address() string {
 	return s.addr
 }
+
+// Addresses returns all the addresses served by the proxy starting with the main one
+func (s *ProxySigner) Addresses() []string {
+	res := []string{s.addr}
+
+	for _, ma := range s.mas {
+		maddr, err := ma.Address()
+		if err != nil {
+			continue
+		}
+
+		if maddr.String() != s.addr {
+			res = append(res, maddr.String())
+		}
+	}
+
+	return res
+}
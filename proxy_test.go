@@ -0,0 +1,255 @@
+package ams
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/wc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyRoutesMultipleMultisigs(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	acc3 := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	ma1, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+	ma2, err := crypto.MultisigAccountWithParams(1, 1, []types.Address{acc2.Address, acc3.Address})
+	assert.NoError(t, err)
+
+	maddr1, err := ma1.Address()
+	assert.NoError(t, err)
+	maddr2, err := ma2.Address()
+	assert.NoError(t, err)
+
+	tx1, err := transaction.MakePaymentTxn(maddr1.String(), maddr1.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx2, err := transaction.MakePaymentTxn(maddr2.String(), maddr2.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx3, err := transaction.MakePaymentTxn(other.Address.String(), other.Address.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	s, err := MakeProxySigner(maddr1.String(), WithProxySignerMultisigs([]crypto.MultisigAccount{ma1, ma2}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{maddr1.String(), maddr2.String()}, s.Addresses())
+
+	routes, err := s.routes(MakeSignRequest([]types.Transaction{tx1, tx2, tx3}), nil)
+	assert.NoError(t, err)
+	assert.Len(t, routes, 3)
+
+	assert.Equal(t, maddr1, routes[0].addr)
+	assert.Equal(t, 2, routes[0].need)
+	assert.True(t, routes[0].member(acc1.Address.String()))
+	assert.False(t, routes[0].member(acc3.Address.String()))
+
+	assert.Equal(t, maddr2, routes[1].addr)
+	assert.Equal(t, 1, routes[1].need)
+	assert.True(t, routes[1].member(acc3.Address.String()))
+
+	assert.Nil(t, routes[2])
+}
+
+func TestProxyRouteConvertAndMerge(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	// single signature without the signer set, as returned by some wallets
+	_, stx1, err := crypto.SignTransaction(acc1.PrivateKey, tx)
+	assert.NoError(t, err)
+
+	var single types.SignedTxn
+	err = msgpack.Decode(stx1, &single)
+	assert.NoError(t, err)
+
+	single.AuthAddr = types.ZeroAddress
+	stx1 = msgpack.Encode(single)
+
+	_, stx2, err := crypto.SignMultisigTransaction(acc2.PrivateKey, ma, tx)
+	assert.NoError(t, err)
+
+	r := &proxyRoute{
+		ma:   &ma,
+		addr: maddr,
+		need: 2,
+	}
+
+	c1, err := r.convert(stx1, acc1.Address.String())
+	assert.NoError(t, err)
+	c2, err := r.convert(stx2, acc2.Address.String())
+	assert.NoError(t, err)
+
+	merged, err := r.merge([][]byte{c1, c2})
+	assert.NoError(t, err)
+
+	var stx types.SignedTxn
+	err = msgpack.Decode(merged, &stx)
+	assert.NoError(t, err)
+
+	assert.True(t, crypto.VerifyMultisig(maddr, append([]byte("TX"), msgpack.Encode(tx)...), stx.Msig))
+	assert.True(t, stx.AuthAddr.IsZero())
+}
+
+func TestProxyRoutesSkipExistingSignatures(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	acc3 := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address, acc3.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx1, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx2, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 2, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	_, bs1, err := crypto.SignMultisigTransaction(acc1.PrivateKey, ma, tx1)
+	assert.NoError(t, err)
+	_, bs2, err := crypto.SignMultisigTransaction(acc1.PrivateKey, ma, tx2)
+	assert.NoError(t, err)
+	_, bs2, err = crypto.AppendMultisigTransaction(acc2.PrivateKey, ma, bs2)
+	assert.NoError(t, err)
+
+	var partial, full types.SignedTxn
+	assert.NoError(t, msgpack.Decode(bs1, &partial))
+	assert.NoError(t, msgpack.Decode(bs2, &full))
+
+	s, err := MakeProxySigner(maddr.String(), WithProxySignerMultisig(&ma))
+	assert.NoError(t, err)
+
+	routes, err := s.routes(MakeSignRequest([]types.Transaction{tx1, tx2}), []types.SignedTxn{partial, full})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, routes[0].need)
+	assert.False(t, routes[0].member(acc1.Address.String()))
+	assert.True(t, routes[0].member(acc2.Address.String()))
+	assert.Nil(t, routes[1])
+}
+
+func TestProxySignPartialSkipsFailedPeer(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	acc3 := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address, acc3.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	keys := map[string]crypto.Account{
+		acc1.Address.String(): acc1,
+		acc2.Address.String(): acc2,
+		acc3.Address.String(): acc3,
+	}
+
+	peers := []PeerAddr{
+		{Address: acc1.Address.String()},
+		{Address: acc2.Address.String()},
+		{Address: acc3.Address.String()},
+	}
+
+	s, err := MakeProxySigner(maddr.String(),
+		WithProxySignerMultisig(&ma),
+		WithProxySignerPeersCallback(func() []PeerAddr { return peers }),
+	)
+	assert.NoError(t, err)
+
+	var requested []string
+
+	s.sign = func(p PeerAddr, req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error) {
+		requested = append(requested, p.Address)
+
+		if p.Address == acc1.Address.String() {
+			return MakeRejectedSignResponse("rejected"), nil
+		}
+
+		ls, err := MakeLocalSigner(p.Address, keys[p.Address].PrivateKey)
+		assert.NoError(t, err)
+
+		return ls.Sign(req)
+	}
+
+	resp, err := s.Sign(MakeSignRequest([]types.Transaction{tx}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{acc1.Address.String(), acc2.Address.String(), acc3.Address.String()}, requested)
+
+	stxs, err := wc.DecodeAlgoSignResponse(*resp)
+	assert.NoError(t, err)
+
+	var stx types.SignedTxn
+	assert.NoError(t, msgpack.Decode(stxs[0], &stx))
+	assert.True(t, crypto.VerifyMultisig(maddr, append([]byte("TX"), msgpack.Encode(tx)...), stx.Msig))
+
+	// a single remaining member cannot reach the threshold
+	sign := s.sign
+	s.sign = func(p PeerAddr, req wc.AlgoSignRequest) (*wc.AlgoSignResponse, error) {
+		if p.Address == acc3.Address.String() {
+			return nil, errors.New("disconnected")
+		}
+
+		return sign(p, req)
+	}
+
+	_, err = s.Sign(MakeSignRequest([]types.Transaction{tx}))
+	assert.Error(t, err)
+}
+
+func TestProxyRoutesEmptySigners(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx1, err := transaction.MakePaymentTxn(maddr.String(), other.Address.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx2, err := transaction.MakePaymentTxn(other.Address.String(), maddr.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	// the counterparty transaction of a dapp group is marked with an empty signers list
+	bs, err := json.Marshal(MakeSignRequest([]types.Transaction{tx1, tx2}))
+	assert.NoError(t, err)
+
+	var req wc.AlgoSignRequest
+	assert.NoError(t, json.Unmarshal([]byte(strings.Replace(string(bs), `"}]]`, `","signers":[]}]]`, 1)), &req))
+	assert.Nil(t, req.Params[0][0].Signers)
+	assert.NotNil(t, req.Params[0][1].Signers)
+
+	s, err := MakeProxySigner(maddr.String(), WithProxySignerMultisig(&ma))
+	assert.NoError(t, err)
+
+	routes, err := s.routes(req, nil)
+	assert.NoError(t, err)
+	assert.Len(t, routes, 2)
+
+	assert.Equal(t, maddr, routes[0].addr)
+	assert.Nil(t, routes[1])
+}
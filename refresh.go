@@ -0,0 +1,88 @@
+package ams
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// Refresh re-stamps the validity window, genesis and fee of unsigned transactions from the suggested params.
+// The validity window length is kept if the network allows it. Fees are never lowered and zero fees of pooled
+// groups are kept. The group id is recomputed for grouped transactions. Signed transactions are refused.
+func Refresh(stxs []types.SignedTxn, sp types.SuggestedParams) ([]TxnChange, error) {
+	err := checkUnsigned(stxs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stxs) > MaxGroupSize {
+		return nil, errors.Errorf("too many transactions in a group - got: %d, max: %d", len(stxs), MaxGroupSize)
+	}
+
+	var changes []TxnChange
+
+	change := func(i int, field string, old, new interface{}) {
+		o := fmt.Sprint(old)
+		n := fmt.Sprint(new)
+
+		if o != n {
+			changes = append(changes, TxnChange{Index: i, Field: field, Old: o, New: n})
+		}
+	}
+
+	var grouped bool
+
+	maxWindow := uint64(sp.LastRoundValid) - uint64(sp.FirstRoundValid)
+
+	for i := range stxs {
+		txn := &stxs[i].Txn
+
+		if txn.Group != (types.Digest{}) {
+			grouped = true
+		}
+
+		window := uint64(txn.LastValid) - uint64(txn.FirstValid)
+		if txn.LastValid < txn.FirstValid || window == 0 || window > maxWindow {
+			window = maxWindow
+		}
+
+		first := sp.FirstRoundValid
+		last := first + types.Round(window)
+
+		change(i, "first valid", txn.FirstValid, first)
+		change(i, "last valid", txn.LastValid, last)
+		change(i, "genesis id", txn.GenesisID, sp.GenesisID)
+		change(i, "genesis hash", formatDigest(txn.GenesisHash), formatDigest(types.Digest(sp.GenesisHash)))
+
+		txn.FirstValid = first
+		txn.LastValid = last
+		txn.GenesisID = sp.GenesisID
+		copy(txn.GenesisHash[:], sp.GenesisHash)
+
+		if txn.Fee == 0 && len(stxs) > 1 {
+			continue
+		}
+
+		fee, err := suggestedFee(*txn, sp)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compute suggested fee - index: %d", i)
+		}
+
+		if fee > uint64(txn.Fee) {
+			change(i, "fee", fmt.Sprintf("%d microALGO", txn.Fee), fmt.Sprintf("%d microALGO", fee))
+			txn.Fee = types.MicroAlgos(fee)
+		}
+	}
+
+	if grouped {
+		gcs, err := AssignGroup(stxs)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to assign group")
+		}
+
+		changes = append(changes, gcs...)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,49 @@
+package ams
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefresh(t *testing.T) {
+	stxs := makeTestPayments(t, crypto.GenerateAccount(), 2)
+
+	_, err := AssignGroup(stxs)
+	assert.NoError(t, err)
+
+	old := stxs[0].Txn.Group
+
+	sp := types.SuggestedParams{
+		Fee:             0,
+		MinFee:          2000,
+		FirstRoundValid: 5000,
+		LastRoundValid:  6000,
+		GenesisID:       "testnet-v1.0",
+		GenesisHash:     make([]byte, 32),
+	}
+	sp.GenesisHash[0] = 1
+
+	changes, err := Refresh(stxs, sp)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, changes)
+
+	for _, stx := range stxs {
+		assert.Equal(t, types.Round(5000), stx.Txn.FirstValid)
+		// the original 1000 rounds window is kept
+		assert.Equal(t, types.Round(6000), stx.Txn.LastValid)
+		assert.Equal(t, "testnet-v1.0", stx.Txn.GenesisID)
+		assert.Equal(t, byte(1), stx.Txn.GenesisHash[0])
+		assert.Equal(t, types.MicroAlgos(2000), stx.Txn.Fee)
+		assert.NotEqual(t, old, stx.Txn.Group)
+	}
+
+	assert.Equal(t, stxs[0].Txn.Group, stxs[1].Txn.Group)
+
+	stxs[1].Sig = types.Signature{1}
+
+	_, err = Refresh(stxs, sp)
+	assert.Error(t, err)
+}
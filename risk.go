@@ -0,0 +1,330 @@
+package ams
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+var severityNames = map[Severity]string{
+	SeverityInfo:     "info",
+	SeverityWarning:  "warning",
+	SeverityCritical: "critical",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	for k, v := range severityNames {
+		if v == string(text) {
+			*s = k
+			return nil
+		}
+	}
+
+	return errors.Errorf("unknown severity: %s", text)
+}
+
+const (
+	// DefaultRiskMaxFee is the fee in microALGO above which a transaction is flagged
+	DefaultRiskMaxFee = 10000
+	// DefaultRiskMaxValidity is the validity window length in rounds above which a transaction is flagged
+	DefaultRiskMaxValidity = 500
+)
+
+// RiskConfirmPhrase has to be typed to approve transactions with critical findings
+const RiskConfirmPhrase = "I ACCEPT THE RISK"
+
+// RiskFinding is a single risk of a transaction or of the whole group when Index is -1
+type RiskFinding struct {
+	Index    int      `json:"index" yaml:"index"`
+	Severity Severity `json:"severity" yaml:"severity"`
+	Code     string   `json:"code" yaml:"code"`
+	Message  string   `json:"message" yaml:"message"`
+}
+
+func (f RiskFinding) String() string {
+	target := "group"
+	if f.Index >= 0 {
+		target = fmt.Sprintf("transaction #%d", f.Index)
+	}
+
+	return fmt.Sprintf("[%s] %s: %s - %s", strings.ToUpper(f.Severity.String()), target, f.Code, f.Message)
+}
+
+// MaxSeverity returns the highest severity of the findings and false if there are none
+func MaxSeverity(findings []RiskFinding) (Severity, bool) {
+	var max Severity
+
+	for _, f := range findings {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+
+	return max, len(findings) > 0
+}
+
+// ConfirmFindings asks to confirm the action with y, or with the confirmation phrase if any of the findings is critical
+func ConfirmFindings(r *bufio.Reader, findings []RiskFinding, action string) (bool, error) {
+	if max, ok := MaxSeverity(findings); ok && max == SeverityCritical {
+		fmt.Printf("[!!!] CRITICAL RISK - type \"%s\" to %s:\n", RiskConfirmPhrase, action)
+
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return false, errors.Wrap(err, "failed to read confirmation")
+		}
+
+		return strings.TrimSpace(line) == RiskConfirmPhrase, nil
+	}
+
+	if len(action) > 0 {
+		action = strings.ToUpper(action[:1]) + action[1:]
+	}
+
+	fmt.Printf("%s? [y/N]\n", action)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return false, errors.Wrap(err, "failed to read confirmation")
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// RiskAnalyzer flags the dangerous parts of transactions before they are signed
+type RiskAnalyzer struct {
+	own   map[types.Address]bool
+	known map[types.Address]bool
+	book  *AddressBook
+
+	maxFee      uint64
+	maxValidity uint64
+}
+
+type RiskAnalyzerOption func(a *RiskAnalyzer) error
+
+func addAddresses(m map[types.Address]bool, addrs []string) error {
+	for _, s := range addrs {
+		addr, err := types.DecodeAddress(s)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode address: %s", s)
+		}
+
+		m[addr] = true
+	}
+
+	return nil
+}
+
+// WithRiskOwnAddresses sets the accounts the transactions are expected to be sent from
+func WithRiskOwnAddresses(addrs []string) RiskAnalyzerOption {
+	return func(a *RiskAnalyzer) error {
+		return addAddresses(a.own, addrs)
+	}
+}
+
+// WithRiskKnownAddresses sets the expected receivers; receivers are checked only if any known or labeled address is set
+func WithRiskKnownAddresses(addrs []string) RiskAnalyzerOption {
+	return func(a *RiskAnalyzer) error {
+		return addAddresses(a.known, addrs)
+	}
+}
+
+// WithRiskAddressBook treats the labeled addresses as known and flags known bad and look-alike addresses
+func WithRiskAddressBook(b *AddressBook) RiskAnalyzerOption {
+	return func(a *RiskAnalyzer) error {
+		a.book = b
+		return nil
+	}
+}
+
+func WithRiskMaxFee(fee uint64) RiskAnalyzerOption {
+	return func(a *RiskAnalyzer) error {
+		a.maxFee = fee
+		return nil
+	}
+}
+
+func WithRiskMaxValidity(rounds uint64) RiskAnalyzerOption {
+	return func(a *RiskAnalyzer) error {
+		a.maxValidity = rounds
+		return nil
+	}
+}
+
+func MakeRiskAnalyzer(opts ...RiskAnalyzerOption) (*RiskAnalyzer, error) {
+	a := &RiskAnalyzer{
+		own:         map[types.Address]bool{},
+		known:       map[types.Address]bool{},
+		maxFee:      DefaultRiskMaxFee,
+		maxValidity: DefaultRiskMaxValidity,
+	}
+
+	for _, opt := range opts {
+		err := opt(a)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// checksReceivers reports whether there are known or labeled addresses to check the receivers against
+func (a *RiskAnalyzer) checksReceivers() bool {
+	return len(a.known) > 0 || len(a.book.Addresses()) > 0
+}
+
+func (a *RiskAnalyzer) checkReceiver(add func(Severity, string, string), kind string, addr types.Address) {
+	if addr.IsZero() {
+		return
+	}
+
+	l, labeled := a.book.Lookup(addr.String())
+	switch {
+	case labeled && l.Bad:
+		add(SeverityCritical, "known-bad-address", fmt.Sprintf("%s is a known bad address: %s (%s)", kind, addr, l.Label))
+		return
+	case labeled && l.Resembles != nil:
+		add(SeverityCritical, "address-poisoning", fmt.Sprintf("%s %s resembles %s (%s)", kind, addr, l.Resembles.Label, l.Resembles.Address))
+		return
+	case labeled:
+		return
+	}
+
+	if !a.checksReceivers() || a.known[addr] || a.own[addr] {
+		return
+	}
+
+	add(SeverityWarning, "unknown-receiver", fmt.Sprintf("%s is not a known address: %s", kind, addr))
+}
+
+// AnalyzeTxn returns the findings of a single transaction
+func (a *RiskAnalyzer) AnalyzeTxn(index int, txn types.Transaction) []RiskFinding {
+	var res []RiskFinding
+
+	add := func(s Severity, code string, msg string) {
+		res = append(res, RiskFinding{Index: index, Severity: s, Code: code, Message: msg})
+	}
+
+	switch {
+	case !txn.RekeyTo.IsZero() && txn.RekeyTo == txn.Sender:
+		// the account was likely rekeyed away from a compromised or retired key
+		add(SeverityCritical, "rekey-back", "the sender account gets controlled by its own key again")
+	case !txn.RekeyTo.IsZero():
+		add(SeverityCritical, "rekey", fmt.Sprintf("the sender account gets controlled by %s", txn.RekeyTo))
+		a.checkReceiver(add, "rekey to", txn.RekeyTo)
+	}
+
+	if len(a.own) > 0 && !a.own[txn.Sender] {
+		add(SeverityWarning, "foreign-sender", fmt.Sprintf("the sender is not one of our accounts: %s", txn.Sender))
+	}
+
+	if uint64(txn.Fee) > a.maxFee {
+		add(SeverityWarning, "high-fee", fmt.Sprintf("the fee of %s is above %s", FormatAlgos(uint64(txn.Fee)), FormatAlgos(a.maxFee)))
+	}
+
+	if txn.LastValid < txn.FirstValid {
+		add(SeverityWarning, "inverted-validity", fmt.Sprintf("the last valid round %d is before the first valid round %d, the transaction can never be confirmed", txn.LastValid, txn.FirstValid))
+	} else if uint64(txn.LastValid-txn.FirstValid) > a.maxValidity {
+		add(SeverityInfo, "long-validity", fmt.Sprintf("the transaction stays valid for %d rounds", validityRounds(txn)))
+	}
+
+	if len(txn.Note) > 0 && DecodeNote(txn.Note).Sanitized {
+		add(SeverityWarning, "note-control-chars", "the note contains terminal escape sequences or control characters")
+	}
+
+	switch txn.Type {
+	case types.PaymentTx:
+		a.checkReceiver(add, "receiver", txn.Receiver)
+
+		if !txn.CloseRemainderTo.IsZero() {
+			add(SeverityCritical, "close-remainder-to", fmt.Sprintf("the whole ALGO balance is sent and the account is closed to %s", txn.CloseRemainderTo))
+			a.checkReceiver(add, "close remainder to", txn.CloseRemainderTo)
+		}
+
+	case types.AssetTransferTx:
+		a.checkReceiver(add, "asset receiver", txn.AssetReceiver)
+
+		if !txn.AssetCloseTo.IsZero() {
+			add(SeverityCritical, "asset-close-to", fmt.Sprintf("the whole ASA #%d balance is sent to %s", txn.XferAsset, txn.AssetCloseTo))
+			a.checkReceiver(add, "asset close to", txn.AssetCloseTo)
+		}
+
+		if !txn.AssetSender.IsZero() {
+			add(SeverityWarning, "clawback", fmt.Sprintf("ASA #%d is clawed back from %s", txn.XferAsset, txn.AssetSender))
+		}
+
+	case types.AssetConfigTx:
+		if txn.ConfigAsset != 0 && txn.AssetParams == (types.AssetParams{}) {
+			add(SeverityCritical, "asset-destroy", fmt.Sprintf("ASA #%d is destroyed", txn.ConfigAsset))
+		}
+
+	case types.KeyRegistrationTx:
+		if txn.Nonparticipation {
+			add(SeverityCritical, "nonparticipation", "the account is marked as non-participating permanently")
+		}
+
+	case types.ApplicationCallTx:
+		switch txn.OnCompletion {
+		case types.DeleteApplicationOC:
+			add(SeverityCritical, "app-delete", fmt.Sprintf("application %d is deleted", txn.ApplicationID))
+		case types.UpdateApplicationOC:
+			add(SeverityCritical, "app-update", fmt.Sprintf("the programs of application %d are replaced", txn.ApplicationID))
+		case types.ClearStateOC:
+			add(SeverityWarning, "app-clear", fmt.Sprintf("the local state of application %d is removed", txn.ApplicationID))
+		}
+	}
+
+	return res
+}
+
+// Analyze returns the findings of every transaction and of the group as a whole
+func (a *RiskAnalyzer) Analyze(txs []types.Transaction) []RiskFinding {
+	var res []RiskFinding
+
+	for i, txn := range txs {
+		res = append(res, a.AnalyzeTxn(i, txn)...)
+	}
+
+	if len(txs) < 2 {
+		return res
+	}
+
+	gid := txs[0].Group
+	for _, txn := range txs[1:] {
+		if txn.Group != gid {
+			res = append(res, RiskFinding{Index: -1, Severity: SeverityCritical, Code: "group-mismatch", Message: "the transactions have different group ids"})
+			return res
+		}
+	}
+
+	if gid == (types.Digest{}) {
+		res = append(res, RiskFinding{Index: -1, Severity: SeverityWarning, Code: "not-atomic", Message: "the transactions are not grouped and may be executed separately"})
+	}
+
+	return res
+}
@@ -0,0 +1,170 @@
+package ams
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func riskCodes(findings []RiskFinding) map[string]Severity {
+	res := map[string]Severity{}
+	for _, f := range findings {
+		res[f.Code] = f.Severity
+	}
+
+	return res
+}
+
+func TestRiskAnalyzer(t *testing.T) {
+	stxs := makeTestPayments(t, crypto.GenerateAccount(), 2)
+	other := crypto.GenerateAccount()
+
+	a, err := MakeRiskAnalyzer(
+		WithRiskOwnAddresses([]string{stxs[0].Txn.Sender.String()}),
+		WithRiskKnownAddresses([]string{stxs[0].Txn.Receiver.String()}),
+	)
+	assert.NoError(t, err)
+
+	txs := []types.Transaction{stxs[0].Txn, stxs[1].Txn}
+
+	codes := riskCodes(a.Analyze(txs))
+	assert.Equal(t, SeverityWarning, codes["not-atomic"])
+	assert.Equal(t, SeverityInfo, codes["long-validity"])
+	assert.Len(t, codes, 2)
+
+	txs[0].RekeyTo = other.Address
+	txs[0].Fee = 1000000
+	txs[1].Sender = other.Address
+	txs[1].Receiver = other.Address
+	txs[1].CloseRemainderTo = other.Address
+	txs[1].LastValid = txs[1].FirstValid + 10
+	txs[1].Group = types.Digest{1}
+
+	findings := a.Analyze(txs)
+	codes = riskCodes(findings)
+
+	assert.Equal(t, SeverityCritical, codes["rekey"])
+	assert.Equal(t, SeverityWarning, codes["high-fee"])
+	assert.Equal(t, SeverityWarning, codes["foreign-sender"])
+	assert.Equal(t, SeverityWarning, codes["unknown-receiver"])
+	assert.Equal(t, SeverityCritical, codes["close-remainder-to"])
+	assert.Equal(t, SeverityCritical, codes["group-mismatch"])
+
+	max, ok := MaxSeverity(findings)
+	assert.True(t, ok)
+	assert.Equal(t, SeverityCritical, max)
+}
+
+func TestRiskAnalyzerAddressBookReceivers(t *testing.T) {
+	treasury := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	txn := types.Transaction{
+		Type:   types.PaymentTx,
+		Header: types.Header{Sender: other.Address, FirstValid: 1, LastValid: 10},
+		PaymentTxnFields: types.PaymentTxnFields{
+			Receiver: treasury.Address,
+		},
+	}
+
+	// receivers are not checked without known or labeled addresses
+	a, err := MakeRiskAnalyzer(WithRiskAddressBook(&AddressBook{}))
+	assert.NoError(t, err)
+	assert.Empty(t, a.AnalyzeTxn(0, txn))
+
+	b, err := MakeAddressBook(WithAddressBookEntry(AddressBookEntry{Address: treasury.Address.String(), Label: "Treasury"}))
+	assert.NoError(t, err)
+
+	a, err = MakeRiskAnalyzer(WithRiskAddressBook(b))
+	assert.NoError(t, err)
+	assert.Empty(t, a.AnalyzeTxn(0, txn))
+
+	txn.Receiver = other.Address
+	assert.Equal(t, map[string]Severity{"unknown-receiver": SeverityWarning}, riskCodes(a.AnalyzeTxn(0, txn)))
+}
+
+func TestRiskAnalyzerAppCall(t *testing.T) {
+	a, err := MakeRiskAnalyzer()
+	assert.NoError(t, err)
+
+	txn := types.Transaction{
+		Type: types.ApplicationCallTx,
+		ApplicationFields: types.ApplicationFields{
+			ApplicationCallTxnFields: types.ApplicationCallTxnFields{
+				ApplicationID: 123,
+				OnCompletion:  types.DeleteApplicationOC,
+			},
+		},
+	}
+
+	findings := a.AnalyzeTxn(0, txn)
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "[CRITICAL] transaction #0: app-delete - application 123 is deleted", findings[0].String())
+}
+
+func TestRiskAnalyzerRekeyBack(t *testing.T) {
+	a, err := MakeRiskAnalyzer()
+	assert.NoError(t, err)
+
+	acc := crypto.GenerateAccount()
+
+	codes := riskCodes(a.AnalyzeTxn(0, types.Transaction{
+		Type:   types.PaymentTx,
+		Header: types.Header{Sender: acc.Address, RekeyTo: acc.Address},
+		PaymentTxnFields: types.PaymentTxnFields{
+			Receiver: acc.Address,
+		},
+	}))
+
+	assert.Equal(t, SeverityCritical, codes["rekey-back"])
+	assert.NotContains(t, codes, "rekey")
+}
+
+func TestConfirmFindings(t *testing.T) {
+	warning := []RiskFinding{{Severity: SeverityWarning, Code: "high-fee"}}
+	critical := []RiskFinding{{Severity: SeverityCritical, Code: "rekey"}}
+
+	ok, err := ConfirmFindings(bufio.NewReader(strings.NewReader("y\n")), warning, "sign")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = ConfirmFindings(bufio.NewReader(strings.NewReader("\n")), nil, "sign")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = ConfirmFindings(bufio.NewReader(strings.NewReader("y\n")), warning, "")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = ConfirmFindings(bufio.NewReader(strings.NewReader("y\n")), critical, "sign")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = ConfirmFindings(bufio.NewReader(strings.NewReader(RiskConfirmPhrase+"\n")), critical, "sign")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRiskAnalyzerInvertedValidity(t *testing.T) {
+	a, err := MakeRiskAnalyzer()
+	assert.NoError(t, err)
+
+	acc := crypto.GenerateAccount()
+
+	txn := types.Transaction{
+		Type:   types.PaymentTx,
+		Header: types.Header{Sender: acc.Address, FirstValid: 1000, LastValid: 10},
+	}
+
+	codes := riskCodes(a.AnalyzeTxn(0, txn))
+	assert.Equal(t, SeverityWarning, codes["inverted-validity"])
+	assert.NotContains(t, codes, "long-validity")
+
+	d := DecodeTxnGroup([]types.SignedTxn{{Txn: txn}}).Txns[0]
+	assert.Equal(t, uint64(0), d.ValidityRounds)
+	assert.Contains(t, d.Text(), "INVERTED")
+}
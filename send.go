@@ -0,0 +1,106 @@
+package ams
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// SendRunner signs a group with the signer and submits it to algod
+type SendRunner struct {
+	stxs []types.SignedTxn
+
+	s    Signer
+	ac   *algod.Client
+	wait uint64
+	json bool
+}
+
+type SendRunnerOption func(r *SendRunner)
+
+func WithSendRunnerSigner(s Signer) SendRunnerOption {
+	return func(r *SendRunner) {
+		r.s = s
+	}
+}
+
+func WithSendRunnerAlgod(ac *algod.Client) SendRunnerOption {
+	return func(r *SendRunner) {
+		r.ac = ac
+	}
+}
+
+// WithSendRunnerWaitRounds sets the number of rounds to wait for the confirmation (0 - no waiting)
+func WithSendRunnerWaitRounds(rounds uint64) SendRunnerOption {
+	return func(r *SendRunner) {
+		r.wait = rounds
+	}
+}
+
+// WithSendRunnerJson prints the submission result as json
+func WithSendRunnerJson(json bool) SendRunnerOption {
+	return func(r *SendRunner) {
+		r.json = json
+	}
+}
+
+func MakeSendRunner(stxs []types.SignedTxn, opts ...SendRunnerOption) (*SendRunner, error) {
+	r := &SendRunner{
+		stxs: stxs,
+		wait: DefaultWaitRounds,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if len(r.stxs) == 0 {
+		return nil, errors.New("no transactions to send")
+	}
+
+	if r.s == nil {
+		return nil, errors.New("missing signer")
+	}
+
+	if r.ac == nil {
+		return nil, errors.New("missing algod client")
+	}
+
+	return r, nil
+}
+
+func (r *SendRunner) Run() error {
+	signed, err := SignMissing(r.s, r.stxs)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign transactions")
+	}
+
+	for i, bs := range signed {
+		var stx types.SignedTxn
+		err = msgpack.Decode(bs, &stx)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode signed transaction")
+		}
+
+		if !IsFullySigned(stx) {
+			return errors.Errorf("transaction #%d is not fully signed", i)
+		}
+	}
+
+	if !r.json {
+		fmt.Println("Sending signed transactions..")
+	}
+
+	res, err := SubmitGroup(r.ac, signed, r.wait)
+	if res != nil {
+		perr := PrintSubmitResult(res, r.json)
+		if perr != nil {
+			return perr
+		}
+	}
+
+	return err
+}
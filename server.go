@@ -0,0 +1,166 @@
+package ams
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dragmz/wc"
+	"github.com/pkg/errors"
+)
+
+// AddressesSigner is implemented by signers that serve more than one address
+type AddressesSigner interface {
+	Addresses() []string
+}
+
+// Server is a WalletConnect wallet endpoint that advertises every address served by its signer
+// and answers failed sign requests with an error instead of ending the session.
+// It is adapted from wc.Server of github.com/dragmz/wc v0.0.0-20230226222146-9f7d66fb732f,
+// which advertises only the signer's Address and drops the sign response errors.
+type Server struct {
+	c    *wc.Conn
+	dapp string
+
+	s Signer
+
+	debug bool
+}
+
+type ServerOption func(s *Server)
+
+func WithServerDebug(debug bool) ServerOption {
+	return func(s *Server) {
+		s.debug = debug
+	}
+}
+
+func MakeServer(uri wc.Uri, signer Signer, opts ...ServerOption) (*Server, error) {
+	s := &Server{
+		s: signer,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	conn, err := wc.MakeConn(
+		wc.WithConnDebug(s.debug),
+		wc.WithConnKey(uri.Key),
+		wc.WithConnHost(uri.Url.Host),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make connection")
+	}
+
+	err = conn.Subscribe(uri.Topic)
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "wallet failed to subscribe to topic")
+	}
+
+	s.c = conn
+
+	return s, nil
+}
+
+// Close closes the connection to the bridge which ends Run
+func (s *Server) Close() error {
+	return s.c.Close()
+}
+
+func (s *Server) addresses() []string {
+	if s.s == nil {
+		return nil
+	}
+
+	if as, ok := s.s.(AddressesSigner); ok {
+		return as.Addresses()
+	}
+
+	return []string{s.s.Address()}
+}
+
+func (s *Server) Run() error {
+	for {
+		incoming, err := s.c.Read()
+		if err != nil {
+			return errors.Wrap(err, "wallet failed to read")
+		}
+
+		switch incoming.Method {
+		case "algo_signTxn":
+			var req wc.AlgoSignRequest
+			err = json.Unmarshal(incoming.Result, &req)
+			if err != nil {
+				return errors.Wrap(err, "failed to unmarshal sign request")
+			}
+
+			if len(req.Params) == 0 || s.s == nil {
+				continue
+			}
+
+			resp, err := s.s.Sign(req)
+			if err != nil {
+				fmt.Println("Failed to sign request:", err)
+				resp = MakeRejectedSignResponse(err.Error())
+			}
+
+			header := wc.MakeResponseHeader(incoming.Id)
+			header.Error = resp.Error
+
+			err = s.c.Send(s.dapp, wc.OutgoingResponse{
+				Header: header,
+				Result: resp.Result,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to send sign response")
+			}
+
+		case "wc_sessionRequest":
+			var req wc.SessionRequestRequest
+			err = json.Unmarshal(incoming.Result, &req)
+			if err != nil {
+				return errors.Wrap(err, "failed to unmarshal session request")
+			}
+
+			if len(req.Params) == 0 {
+				continue
+			}
+
+			peer := wc.MakeTopic()
+
+			err = s.c.Subscribe(peer)
+			if err != nil {
+				return errors.Wrap(err, "failed to subscribe to peer topic")
+			}
+
+			addresses := s.addresses()
+
+			if s.debug {
+				fmt.Println("Session addresses:", addresses)
+			}
+
+			result := wc.SessionRequestResponseResult{
+				PeerId: peer,
+				PeerMeta: wc.SessionRequestPeerMeta{
+					Description: "Algorand Multisig Tools",
+					Url:         "https://github.com/dragmz/ams",
+					Name:        "AMS",
+				},
+				Approved: true,
+				ChainId:  4160,
+				Accounts: addresses,
+			}
+
+			s.dapp = req.Params[0].PeerId
+
+			err = s.c.Send(s.dapp, wc.OutgoingResponse{
+				Header: wc.MakeResponseHeader(incoming.Id),
+				Result: result,
+			})
+			if err != nil {
+				return errors.Wrap(err, "failed to send session response")
+			}
+		}
+	}
+}
@@ -16,6 +16,19 @@ type Signer interface {
 	Address() string
 }
 
+// SignRejectedCode is the ARC-25 error code of a sign request rejected by the user
+const SignRejectedCode = 4001
+
+// MakeRejectedSignResponse builds the response to a sign request declined by the user
+func MakeRejectedSignResponse(message string) *wc.AlgoSignResponse {
+	return &wc.AlgoSignResponse{
+		Error: &wc.Error{
+			Code:    SignRejectedCode,
+			Message: message,
+		},
+	}
+}
+
 type LocalSigner struct {
 	sk    ed25519.PrivateKey
 	ma    *crypto.MultisigAccount
@@ -0,0 +1,390 @@
+package ams
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/wc"
+	"github.com/pkg/errors"
+)
+
+// Simulator runs transaction groups through the algod simulate endpoint which is not covered by the sdk client
+type Simulator struct {
+	url   *url.URL
+	token string
+
+	allowEmpty bool
+
+	hc *http.Client
+}
+
+type SimulatorOption func(s *Simulator)
+
+// WithSimulatorAllowEmptySignatures makes the simulation ignore signatures so that it can run before the group is signed
+func WithSimulatorAllowEmptySignatures(allow bool) SimulatorOption {
+	return func(s *Simulator) {
+		s.allowEmpty = allow
+	}
+}
+
+func MakeSimulator(address string, token string, opts ...SimulatorOption) (*Simulator, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse algod address")
+	}
+
+	s := &Simulator{
+		url:   u,
+		token: token,
+		hc:    http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+func (s *Simulator) AllowsEmptySignatures() bool {
+	return s.allowEmpty
+}
+
+type simulateRequestGroup struct {
+	Txns []types.SignedTxn `codec:"txns"`
+}
+
+type simulateRequest struct {
+	TxnGroups            []simulateRequestGroup `codec:"txn-groups"`
+	AllowEmptySignatures bool                   `codec:"allow-empty-signatures,omitempty"`
+}
+
+type simulateTxnResult struct {
+	TxnResult              models.PendingTransactionResponse `codec:"txn-result"`
+	AppBudgetConsumed      uint64                            `codec:"app-budget-consumed"`
+	LogicSigBudgetConsumed uint64                            `codec:"logic-sig-budget-consumed"`
+}
+
+type simulateGroupResult struct {
+	TxnResults        []simulateTxnResult `codec:"txn-results"`
+	FailureMessage    string              `codec:"failure-message"`
+	FailedAt          []uint64            `codec:"failed-at"`
+	AppBudgetAdded    uint64              `codec:"app-budget-added"`
+	AppBudgetConsumed uint64              `codec:"app-budget-consumed"`
+}
+
+type simulateResponse struct {
+	Version   uint64                `codec:"version"`
+	LastRound uint64                `codec:"last-round"`
+	TxnGroups []simulateGroupResult `codec:"txn-groups"`
+}
+
+// BalanceChange is the net change of an account balance caused by the group; AssetID 0 means ALGO
+type BalanceChange struct {
+	Address string `json:"address"`
+	AssetID uint64 `json:"asset-id"`
+	Amount  int64  `json:"amount"`
+}
+
+type SimulateTxn struct {
+	TxID                   string `json:"txid"`
+	AppBudgetConsumed      uint64 `json:"app-budget-consumed,omitempty"`
+	LogicSigBudgetConsumed uint64 `json:"logic-sig-budget-consumed,omitempty"`
+}
+
+// SimulateResult is the outcome of a group simulation
+type SimulateResult struct {
+	WouldSucceed   bool   `json:"would-succeed"`
+	FailureMessage string `json:"failure-message,omitempty"`
+	// FailedAt is the path to the failed transaction, the first item is its index in the group
+	FailedAt []uint64 `json:"failed-at,omitempty"`
+
+	Round             uint64          `json:"round"`
+	AppBudgetAdded    uint64          `json:"app-budget-added,omitempty"`
+	AppBudgetConsumed uint64          `json:"app-budget-consumed,omitempty"`
+	Txns              []SimulateTxn   `json:"txns"`
+	BalanceChanges    []BalanceChange `json:"balance-changes,omitempty"`
+}
+
+// FailedIndex returns the group index of the failed transaction or -1
+func (r *SimulateResult) FailedIndex() int {
+	if len(r.FailedAt) == 0 {
+		return -1
+	}
+
+	return int(r.FailedAt[0])
+}
+
+// Print writes the result in a human readable form
+func (r *SimulateResult) Print() {
+	if r.WouldSucceed {
+		fmt.Println("Simulation: would succeed, round:", r.Round)
+	} else {
+		fmt.Println("Simulation: WOULD FAIL, round:", r.Round)
+		fmt.Printf("Failed transaction: #%d (path: %v)\n", r.FailedIndex(), r.FailedAt)
+		fmt.Println("Failure:", r.FailureMessage)
+	}
+
+	if r.AppBudgetConsumed > 0 || r.AppBudgetAdded > 0 {
+		fmt.Printf("Opcode budget: %d used of %d\n", r.AppBudgetConsumed, r.AppBudgetAdded)
+	}
+
+	for i, t := range r.Txns {
+		if t.AppBudgetConsumed > 0 || t.LogicSigBudgetConsumed > 0 {
+			fmt.Printf("Transaction #%d: app budget: %d, logic sig budget: %d\n", i, t.AppBudgetConsumed, t.LogicSigBudgetConsumed)
+		}
+	}
+
+	if len(r.BalanceChanges) > 0 {
+		fmt.Println("Balance changes:")
+
+		for _, c := range r.BalanceChanges {
+			unit := "microALGO"
+			if c.AssetID != 0 {
+				unit = fmt.Sprintf("base units of ASA #%d", c.AssetID)
+			}
+
+			fmt.Printf("  %s: %+d %s\n", c.Address, c.Amount, unit)
+		}
+	}
+}
+
+type balanceKey struct {
+	addr  types.Address
+	asset uint64
+}
+
+type balanceChanges map[balanceKey]int64
+
+func (bc balanceChanges) add(addr types.Address, asset uint64, amount int64) {
+	if addr.IsZero() || amount == 0 {
+		return
+	}
+
+	bc[balanceKey{addr: addr, asset: asset}] += amount
+}
+
+// apply accumulates the effects of a simulated transaction including its inner transactions
+func (bc balanceChanges) apply(r models.PendingTransactionResponse) {
+	txn := r.Transaction.Txn
+
+	bc.add(txn.Sender, 0, -int64(txn.Fee))
+
+	switch txn.Type {
+	case types.PaymentTx:
+		bc.add(txn.Sender, 0, -int64(txn.Amount))
+		bc.add(txn.Receiver, 0, int64(txn.Amount))
+		bc.add(txn.Sender, 0, -int64(r.ClosingAmount))
+		bc.add(txn.CloseRemainderTo, 0, int64(r.ClosingAmount))
+
+	case types.AssetTransferTx:
+		// clawback moves the asset from the asset sender instead of the transaction sender
+		from := txn.Sender
+		if !txn.AssetSender.IsZero() {
+			from = txn.AssetSender
+		}
+
+		bc.add(from, uint64(txn.XferAsset), -int64(txn.AssetAmount))
+		bc.add(txn.AssetReceiver, uint64(txn.XferAsset), int64(txn.AssetAmount))
+		bc.add(from, uint64(txn.XferAsset), -int64(r.AssetClosingAmount))
+		bc.add(txn.AssetCloseTo, uint64(txn.XferAsset), int64(r.AssetClosingAmount))
+	}
+
+	for _, inner := range r.InnerTxns {
+		bc.apply(inner)
+	}
+}
+
+func (bc balanceChanges) list() []BalanceChange {
+	var res []BalanceChange
+
+	for k, v := range bc {
+		if v == 0 {
+			continue
+		}
+
+		res = append(res, BalanceChange{
+			Address: k.addr.String(),
+			AssetID: k.asset,
+			Amount:  v,
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Address != res[j].Address {
+			return res[i].Address < res[j].Address
+		}
+		return res[i].AssetID < res[j].AssetID
+	})
+
+	return res
+}
+
+// Simulate runs the group through algod without submitting it
+func (s *Simulator) Simulate(stxs []types.SignedTxn) (*SimulateResult, error) {
+	if len(stxs) == 0 {
+		return nil, errors.New("no transactions to simulate")
+	}
+
+	req := simulateRequest{
+		TxnGroups:            []simulateRequestGroup{{}},
+		AllowEmptySignatures: s.allowEmpty,
+	}
+
+	for _, stx := range stxs {
+		if s.allowEmpty {
+			// partial multisigs would fail verification so all the signatures are dropped; the auth address is kept
+			stx.Sig = types.Signature{}
+			stx.Msig = types.MultisigSig{}
+		}
+
+		req.TxnGroups[0].Txns = append(req.TxnGroups[0].Txns, stx)
+	}
+
+	u := *s.url
+	u.Path = strings.TrimRight(u.Path, "/") + "/v2/transactions/simulate"
+	u.RawQuery = "format=msgpack"
+
+	hreq, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(msgpack.Encode(req)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make simulate request")
+	}
+
+	hreq.Header.Set("Content-Type", "application/msgpack")
+	if len(s.token) > 0 {
+		hreq.Header.Set("X-Algo-API-Token", s.token)
+	}
+
+	hresp, err := s.hc.Do(hreq)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send simulate request")
+	}
+
+	defer hresp.Body.Close()
+
+	body, err := io.ReadAll(hresp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read simulate response")
+	}
+
+	if hresp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("simulate request failed - status: %d, message: %s", hresp.StatusCode, body)
+	}
+
+	var resp simulateResponse
+	err = msgpack.NewLenientDecoder(bytes.NewReader(body)).Decode(&resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode simulate response")
+	}
+
+	if len(resp.TxnGroups) == 0 {
+		return nil, errors.New("empty simulate response")
+	}
+
+	g := resp.TxnGroups[0]
+
+	res := &SimulateResult{
+		WouldSucceed:      len(g.FailureMessage) == 0,
+		FailureMessage:    g.FailureMessage,
+		FailedAt:          g.FailedAt,
+		Round:             resp.LastRound,
+		AppBudgetAdded:    g.AppBudgetAdded,
+		AppBudgetConsumed: g.AppBudgetConsumed,
+	}
+
+	bc := balanceChanges{}
+
+	for i, stx := range stxs {
+		t := SimulateTxn{
+			TxID: crypto.TransactionIDString(stx.Txn),
+		}
+
+		if i < len(g.TxnResults) {
+			tr := g.TxnResults[i]
+
+			t.AppBudgetConsumed = tr.AppBudgetConsumed
+			t.LogicSigBudgetConsumed = tr.LogicSigBudgetConsumed
+
+			// the simulated result may miss the transaction itself so the submitted one is used
+			if tr.TxnResult.Transaction.Txn.Type == "" {
+				tr.TxnResult.Transaction = stx
+			}
+
+			// effects of a failed group are never applied
+			if res.WouldSucceed {
+				bc.apply(tr.TxnResult)
+			}
+		}
+
+		res.Txns = append(res.Txns, t)
+	}
+
+	res.BalanceChanges = bc.list()
+
+	return res, nil
+}
+
+// SignRequestSimulateTxns builds the simulation input of a sign request. Proxied multisig requests carry the
+// cosigner's member address as the auth address, so it is replaced with the multisig address, or cleared if
+// the multisig account is the sender, to simulate the transactions as they will be submitted.
+func SignRequestSimulateTxns(req wc.AlgoSignRequest, ma *crypto.MultisigAccount) ([]types.SignedTxn, error) {
+	if len(req.Params) == 0 {
+		return nil, nil
+	}
+
+	var maddr types.Address
+
+	if ma != nil {
+		var err error
+		maddr, err = ma.Address()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get multisig address")
+		}
+	}
+
+	var stxs []types.SignedTxn
+
+	for i, item := range req.Params[0] {
+		bs, err := base64.StdEncoding.DecodeString(item.TxnBase64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode base64 transaction data - index: %d", i)
+		}
+
+		var txn types.Transaction
+		err = msgpack.Decode(bs, &txn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode transaction msgpack - index: %d", i)
+		}
+
+		stx := types.SignedTxn{Txn: txn}
+
+		if len(item.AuthAddr) > 0 {
+			stx.AuthAddr, err = types.DecodeAddress(item.AuthAddr)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decode auth address - index: %d", i)
+			}
+		}
+
+		if ma != nil && (stx.AuthAddr == maddr || isMultisigMember(*ma, stx.AuthAddr)) {
+			if txn.Sender == maddr {
+				stx.AuthAddr = types.ZeroAddress
+			} else {
+				stx.AuthAddr = maddr
+			}
+		}
+
+		stxs = append(stxs, stx)
+	}
+
+	return stxs, nil
+}
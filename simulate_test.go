@@ -0,0 +1,145 @@
+package ams
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateBalanceChanges(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+
+	tx, err := transaction.MakePaymentTxn(acc1.Address.String(), acc2.Address.String(), 1000, 5000, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx.Fee = 1000
+
+	_, bs, err := crypto.SignTransaction(acc1.PrivateKey, tx)
+	assert.NoError(t, err)
+
+	var stx types.SignedTxn
+	assert.NoError(t, msgpack.Decode(bs, &stx))
+
+	_, u := makeTestAlgod(t, map[string]http.HandlerFunc{
+		"/v2/transactions/simulate": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "msgpack", r.URL.Query().Get("format"))
+
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			var req simulateRequest
+			assert.NoError(t, msgpack.Decode(body, &req))
+
+			assert.True(t, req.AllowEmptySignatures)
+			assert.Len(t, req.TxnGroups[0].Txns, 1)
+			assert.False(t, IsSigned(req.TxnGroups[0].Txns[0]))
+
+			w.Write(msgpack.Encode(simulateResponse{
+				Version:   2,
+				LastRound: 42,
+				TxnGroups: []simulateGroupResult{{
+					TxnResults: []simulateTxnResult{{
+						TxnResult: models.PendingTransactionResponse{Transaction: req.TxnGroups[0].Txns[0]},
+					}},
+				}},
+			}))
+		},
+	})
+
+	sim, err := MakeSimulator(u, "", WithSimulatorAllowEmptySignatures(true))
+	assert.NoError(t, err)
+
+	res, err := sim.Simulate([]types.SignedTxn{stx})
+	assert.NoError(t, err)
+	assert.True(t, res.WouldSucceed)
+	assert.Equal(t, uint64(42), res.Round)
+	assert.Equal(t, -1, res.FailedIndex())
+	assert.ElementsMatch(t, []BalanceChange{
+		{Address: acc1.Address.String(), Amount: -6000},
+		{Address: acc2.Address.String(), Amount: 5000},
+	}, res.BalanceChanges)
+}
+
+func TestSimulateFailure(t *testing.T) {
+	acc := crypto.GenerateAccount()
+
+	tx := types.Transaction{
+		Type: types.ApplicationCallTx,
+		Header: types.Header{
+			Sender:     acc.Address,
+			Fee:        1000,
+			FirstValid: 1,
+			LastValid:  100,
+		},
+		ApplicationFields: types.ApplicationFields{
+			ApplicationCallTxnFields: types.ApplicationCallTxnFields{
+				ApplicationID: 123,
+			},
+		},
+	}
+
+	_, u := makeTestAlgod(t, map[string]http.HandlerFunc{
+		"/v2/transactions/simulate": msgpackHandler(simulateResponse{
+			Version:   2,
+			LastRound: 42,
+			TxnGroups: []simulateGroupResult{{
+				FailureMessage:    "transaction rejected by ApprovalProgram",
+				FailedAt:          []uint64{0},
+				AppBudgetAdded:    700,
+				AppBudgetConsumed: 12,
+				TxnResults: []simulateTxnResult{{
+					AppBudgetConsumed: 12,
+				}},
+			}},
+		}),
+	})
+
+	sim, err := MakeSimulator(u, "")
+	assert.NoError(t, err)
+
+	res, err := sim.Simulate([]types.SignedTxn{{Txn: tx}})
+	assert.NoError(t, err)
+	assert.False(t, res.WouldSucceed)
+	assert.Equal(t, 0, res.FailedIndex())
+	assert.Equal(t, uint64(12), res.AppBudgetConsumed)
+	assert.Equal(t, uint64(12), res.Txns[0].AppBudgetConsumed)
+	assert.Empty(t, res.BalanceChanges)
+}
+
+func TestSignRequestSimulateTxnsProxiedMultisig(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	rekeyed := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx1, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx2, err := transaction.MakePaymentTxn(rekeyed.Address.String(), maddr.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	// the proxy asks a cosigner with its member address as the auth address
+	req := withAuthAddr(MakeSignRequest([]types.Transaction{tx1, tx2}), acc1.Address.String())
+
+	stxs, err := SignRequestSimulateTxns(req, &ma)
+	assert.NoError(t, err)
+	assert.Len(t, stxs, 2)
+
+	assert.True(t, stxs[0].AuthAddr.IsZero())
+	assert.Equal(t, maddr, stxs[1].AuthAddr)
+
+	stxs, err = SignRequestSimulateTxns(req, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, acc1.Address, stxs[0].AuthAddr)
+}
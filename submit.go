@@ -0,0 +1,249 @@
+package ams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// SubmitSignedTxns sends the encoded signed transactions to algod as one group and returns the id of the first one
+func SubmitSignedTxns(ac *algod.Client, signed [][]byte) (string, error) {
+	if ac == nil {
+		return "", errors.New("missing algod client")
+	}
+
+	var group []byte
+	for _, bs := range signed {
+		group = append(group, bs...)
+	}
+
+	id, err := ac.SendRawTransaction(group).Do(context.Background())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to send transactions")
+	}
+
+	return id, nil
+}
+
+// DefaultWaitRounds is the default number of rounds to wait for a confirmation
+const DefaultWaitRounds = 10
+
+type SubmitStatus string
+
+const (
+	SubmitStatusSubmitted SubmitStatus = "submitted"
+	SubmitStatusConfirmed SubmitStatus = "confirmed"
+	SubmitStatusRejected  SubmitStatus = "rejected"
+	SubmitStatusTimeout   SubmitStatus = "timeout"
+)
+
+// SubmitResult describes the outcome of a group submission
+type SubmitResult struct {
+	Status         SubmitStatus `json:"status"`
+	TxIDs          []string     `json:"txids"`
+	ConfirmedRound uint64       `json:"confirmed-round,omitempty"`
+
+	// Error is the raw algod error message and Cause its readable explanation
+	Error string `json:"error,omitempty"`
+	Cause string `json:"cause,omitempty"`
+	// FailedTxID is the id of the rejected transaction if algod reported it
+	FailedTxID string `json:"failed-txid,omitempty"`
+}
+
+var submitErrorCauses = []struct {
+	match string
+	cause string
+}{
+	{"txn dead", "the validity window has passed - refresh the first/last valid rounds"},
+	{"txnDead", "the validity window has passed - refresh the first/last valid rounds"},
+	{"round outside of", "the current round is outside of the validity window"},
+	{"transaction already in ledger", "the transaction has already been submitted"},
+	{"already in ledger", "the transaction has already been submitted"},
+	{"overspend", "the sender balance is too low to cover the amount and fee"},
+	{"below min", "an account would go below its minimum balance"},
+	{"fee too small", "the fee is lower than the minimum fee"},
+	{"txgroup had", "the group fees are too low"},
+	{"should have been authorized by", "the transaction is signed by the wrong account - check the auth address"},
+	{"signedtxn has no sig", "the transaction is not signed"},
+	{"multisig", "the multisig signature is invalid or below the threshold"},
+	{"At least one signature didn't pass verification", "a signature is invalid"},
+	{"incomplete group", "the group is incomplete - submit all the transactions of the group together"},
+	{"inconsistent group", "the group id does not match the submitted transactions"},
+	{"exceeds maximum", "the group has too many transactions"},
+	{"must optin", "the receiver has not opted in to the asset"},
+	{"missing from", "the account has not opted in to the asset"},
+	{"frozen", "the asset holding is frozen"},
+	{"logic eval error", "the smart contract rejected the transaction"},
+	{"rejected by logic", "the logic signature rejected the transaction"},
+	{"genesis", "the transaction belongs to a different network"},
+	{"overlapping lease", "the lease is in use by another transaction"},
+}
+
+var submitErrorTxID = regexp.MustCompile(`transaction ([A-Z2-7]{52})`)
+
+// algodErrorMessage extracts the message from an algod error response body
+func algodErrorMessage(err error) string {
+	msg := err.Error()
+
+	start := strings.Index(msg, "{")
+	if start < 0 {
+		return msg
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+
+	if json.Unmarshal([]byte(msg[start:]), &body) != nil || len(body.Message) == 0 {
+		return msg
+	}
+
+	return body.Message
+}
+
+// ExplainSubmitError turns an algod rejection message into a readable cause, or returns an empty string if unknown
+func ExplainSubmitError(msg string) string {
+	for _, c := range submitErrorCauses {
+		if strings.Contains(msg, c.match) {
+			return c.cause
+		}
+	}
+
+	return ""
+}
+
+// Print writes the result in a human readable form
+func (r *SubmitResult) Print() {
+	fmt.Println("Status:", r.Status)
+
+	for i, id := range r.TxIDs {
+		fmt.Printf("Txid #%d: %s\n", i, id)
+	}
+
+	if r.ConfirmedRound > 0 {
+		fmt.Println("Confirmed round:", r.ConfirmedRound)
+	}
+
+	if len(r.Error) > 0 {
+		fmt.Println("Error:", r.Error)
+	}
+
+	if len(r.FailedTxID) > 0 {
+		fmt.Println("Failed txid:", r.FailedTxID)
+	}
+
+	if len(r.Cause) > 0 {
+		fmt.Println("Cause:", r.Cause)
+	}
+}
+
+// PrintSubmitResult prints the result as indented json or in a human readable form
+func PrintSubmitResult(r *SubmitResult, asJson bool) error {
+	if !asJson {
+		r.Print()
+		return nil
+	}
+
+	bs, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode submit result")
+	}
+
+	fmt.Println(string(bs))
+
+	return nil
+}
+
+func (r *SubmitResult) reject(msg string) {
+	r.Status = SubmitStatusRejected
+	r.Error = msg
+	r.Cause = ExplainSubmitError(msg)
+
+	if m := submitErrorTxID.FindStringSubmatch(msg); m != nil {
+		r.FailedTxID = m[1]
+	}
+}
+
+// SubmitGroup sends the signed group and waits up to waitRounds rounds for its confirmation (0 - no waiting).
+// The result is returned also on rejection or timeout together with the error.
+func SubmitGroup(ac *algod.Client, signed [][]byte, waitRounds uint64) (*SubmitResult, error) {
+	if ac == nil {
+		return nil, errors.New("missing algod client")
+	}
+
+	res := &SubmitResult{}
+
+	for i, bs := range signed {
+		var stx types.SignedTxn
+		err := msgpack.Decode(bs, &stx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode signed transaction - index: %d", i)
+		}
+
+		res.TxIDs = append(res.TxIDs, crypto.TransactionIDString(stx.Txn))
+	}
+
+	if len(res.TxIDs) == 0 {
+		return nil, errors.New("no transactions to submit")
+	}
+
+	ctx := context.Background()
+
+	_, err := SubmitSignedTxns(ac, signed)
+	if err != nil {
+		res.reject(algodErrorMessage(errors.Cause(err)))
+		return res, errors.Wrap(err, "transactions rejected")
+	}
+
+	res.Status = SubmitStatusSubmitted
+
+	if waitRounds == 0 {
+		return res, nil
+	}
+
+	status, err := ac.Status().Do(ctx)
+	if err != nil {
+		return res, errors.Wrap(err, "failed to get algod status")
+	}
+
+	// the group is confirmed atomically so it is enough to track its first transaction
+	txid := res.TxIDs[0]
+	last := status.LastRound + waitRounds
+
+	for round := status.LastRound; ; {
+		info, _, err := ac.PendingTransactionInformation(txid).Do(ctx)
+		// pending lookups may fail behind load balancers so only the definitive answers are used
+		if err == nil {
+			if len(info.PoolError) > 0 {
+				res.reject(info.PoolError)
+				return res, errors.Errorf("transactions rejected: %s", info.PoolError)
+			}
+
+			if info.ConfirmedRound > 0 {
+				res.Status = SubmitStatusConfirmed
+				res.ConfirmedRound = info.ConfirmedRound
+				return res, nil
+			}
+		}
+
+		if round >= last {
+			res.Status = SubmitStatusTimeout
+			return res, errors.Errorf("transactions not confirmed within %d rounds", waitRounds)
+		}
+
+		round++
+
+		_, err = ac.StatusAfterBlock(round).Do(ctx)
+		if err != nil {
+			return res, errors.Wrap(err, "failed to wait for block")
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package ams
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeTestPayments returns n unsigned payments with a flat fee of 1000 from the account to itself
+func makeTestPayments(t *testing.T, acc crypto.Account, n int) []types.SignedTxn {
+	var stxs []types.SignedTxn
+	for i := 0; i < n; i++ {
+		tx, err := transaction.MakePaymentTxnWithFlatFee(acc.Address.String(), acc.Address.String(), 1000, uint64(i), 1000, 2000, nil, "", "test", []byte("test"))
+		assert.NoError(t, err)
+
+		stxs = append(stxs, types.SignedTxn{Txn: tx})
+	}
+
+	return stxs
+}
+
+// makeTestAlgod serves the handlers by request path; a path ending with a slash matches as a prefix,
+// the longest match wins and unmatched paths get 404. The returned url is the address of the server.
+func makeTestAlgod(t *testing.T, handlers map[string]http.HandlerFunc) (*algod.Client, string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := handlers[r.URL.Path]; ok {
+			h(w, r)
+			return
+		}
+
+		var match string
+		for p := range handlers {
+			if strings.HasSuffix(p, "/") && strings.HasPrefix(r.URL.Path, p) && len(p) > len(match) {
+				match = p
+			}
+		}
+
+		if len(match) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"not found"}`))
+			return
+		}
+
+		handlers[match](w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	ac, err := algod.MakeClient(srv.URL, "")
+	assert.NoError(t, err)
+
+	return ac, srv.URL
+}
+
+func jsonHandler(v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(v)
+	}
+}
+
+func msgpackHandler(v interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write(msgpack.Encode(v))
+	}
+}
+
+func TestSubmitGroupConfirmed(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	stxs := makeTestPayments(t, acc, 2)
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	signed, err := SignMissing(s, stxs)
+	assert.NoError(t, err)
+
+	ac, _ := makeTestAlgod(t, map[string]http.HandlerFunc{
+		"/v2/transactions":                 jsonHandler(map[string]interface{}{"txId": "X"}),
+		"/v2/status":                       jsonHandler(map[string]interface{}{"last-round": 100}),
+		"/v2/status/wait-for-block-after/": jsonHandler(map[string]interface{}{"last-round": 101}),
+		"/v2/transactions/pending/":        msgpackHandler(map[string]interface{}{"confirmed-round": 101, "pool-error": ""}),
+	})
+
+	res, err := SubmitGroup(ac, signed, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, SubmitStatusConfirmed, res.Status)
+	assert.Equal(t, uint64(101), res.ConfirmedRound)
+	assert.Len(t, res.TxIDs, 2)
+}
+
+func TestSubmitGroupTimeout(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	stxs := makeTestPayments(t, acc, 2)
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	signed, err := SignMissing(s, stxs)
+	assert.NoError(t, err)
+
+	ac, _ := makeTestAlgod(t, map[string]http.HandlerFunc{
+		"/v2/transactions":                 jsonHandler(map[string]interface{}{"txId": "X"}),
+		"/v2/status":                       jsonHandler(map[string]interface{}{"last-round": 100}),
+		"/v2/status/wait-for-block-after/": jsonHandler(map[string]interface{}{"last-round": 100}),
+		"/v2/transactions/pending/":        msgpackHandler(map[string]interface{}{"pool-error": ""}),
+	})
+
+	res, err := SubmitGroup(ac, signed, 2)
+	assert.Error(t, err)
+	assert.Equal(t, SubmitStatusTimeout, res.Status)
+}
+
+func TestSubmitGroupRejected(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	stxs := makeTestPayments(t, acc, 2)
+
+	s, err := MakeLocalSigner(acc.Address.String(), acc.PrivateKey)
+	assert.NoError(t, err)
+
+	signed, err := SignMissing(s, stxs)
+	assert.NoError(t, err)
+
+	ac, _ := makeTestAlgod(t, map[string]http.HandlerFunc{
+		"/v2/transactions": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"message":"TransactionPool.Remember: transaction BXD2ZJSVWPVGOYHDVZFK4Y6NFZUQNBLVJYF7D5N6PJXIFE3HMMGB: overspend (account X, data {}, tried to spend {1000})"}`))
+		},
+	})
+
+	res, err := SubmitGroup(ac, signed, 5)
+	assert.Error(t, err)
+	assert.Equal(t, SubmitStatusRejected, res.Status)
+	assert.Equal(t, "BXD2ZJSVWPVGOYHDVZFK4Y6NFZUQNBLVJYF7D5N6PJXIFE3HMMGB", res.FailedTxID)
+	assert.Contains(t, res.Cause, "balance is too low")
+	assert.True(t, strings.HasPrefix(res.Error, "TransactionPool.Remember"))
+}
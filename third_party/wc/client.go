@@ -0,0 +1,299 @@
+package wc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+type handlerFunc func(r Incoming) error
+
+type handlers struct {
+	rw sync.Mutex
+	m  map[uint64]handlerFunc
+}
+
+func (h *handlers) Push(key uint64, f handlerFunc) {
+	h.rw.Lock()
+	h.m[key] = f
+	h.rw.Unlock()
+}
+
+func (h *handlers) Pop(key uint64) handlerFunc {
+	h.rw.Lock()
+	f, ok := h.m[key]
+	if ok {
+		delete(h.m, key)
+	}
+	h.rw.Unlock()
+
+	return f
+}
+
+type Client struct {
+	c *Conn
+
+	h  *handlers
+	id atomic.Uint64
+
+	topic string
+
+	urlHandler func(url Uri) error
+
+	debug bool
+}
+
+type ClientOption func(c *Client)
+
+func WithClientDebug(debug bool) ClientOption {
+	return func(c *Client) {
+		c.debug = debug
+	}
+}
+
+func WithClientConn(conn *Conn) ClientOption {
+	return func(c *Client) {
+		c.c = conn
+	}
+}
+
+func WithClientUrlHandler(handler func(uri Uri) error) ClientOption {
+	return func(c *Client) {
+		c.urlHandler = handler
+	}
+}
+
+func MakeClient(meta SessionRequestPeerMeta, opts ...ClientOption) (*Client, *SessionRequestResponseResult, error) {
+	c := &Client{
+		h: &handlers{
+			m: map[uint64]handlerFunc{},
+		},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.c == nil {
+		conn, err := MakeConn(
+			WithConnDebug(c.debug),
+		)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to make client")
+		}
+
+		c.c = conn
+	}
+
+	peer := MakeTopic()
+
+	id := c.id.Add(1)
+
+	req, err := MakeRequestSession(id, peer, meta)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to make session request")
+	}
+
+	topic := MakeTopic()
+
+	err = c.c.Send(topic, req)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to send request session request")
+	}
+
+	url := c.c.MakeUri(topic)
+
+	if c.urlHandler != nil {
+		err = c.urlHandler(url)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to handle url")
+		}
+	}
+
+	err = c.c.Subscribe(peer)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to subscribe")
+	}
+
+	reply, err := c.c.Read()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read")
+	}
+
+	var res SessionRequestResponse
+
+	err = json.Unmarshal(reply.Result, &res)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal wc_sessionRequest response")
+	}
+
+	if res.Error != nil {
+		return nil, &res.Result, res.Error
+	}
+
+	c.topic = topic
+
+	go func() {
+		defer c.c.conn.Close()
+
+		err := func() error {
+			for {
+				incoming, err := c.c.Read()
+				if err != nil {
+					return errors.Wrap(err, "failed to read")
+				}
+
+				handler := c.h.Pop(incoming.Id)
+
+				go func() {
+					err := func() error {
+						if handler != nil {
+							return handler(incoming)
+						}
+
+						if c.debug {
+							fmt.Println("Unhandled message - Id:", incoming.Id, "| JsonRPC:", incoming.JsonRPC, "| Data:", string(incoming.Result))
+						}
+
+						var head Header
+
+						err := json.Unmarshal(incoming.Result, &head)
+						if err != nil {
+							return errors.Wrap(err, "failed to unmarshal request")
+						}
+
+						switch head.Method {
+						case "wc_sessionUpdate":
+							var update SessionUpdateRequest
+
+							fmt.Println("Update:", string(incoming.Result))
+
+							err = json.Unmarshal(incoming.Result, &update)
+							if err != nil {
+								return errors.Wrapf(err, "failed to unmarshal: %s", head.Method)
+							}
+						}
+
+						return nil
+					}()
+
+					// TODO: send response if incoming.Method is set
+
+					if err != nil {
+						fmt.Println("Handler error:", err)
+					}
+				}()
+			}
+		}()
+
+		if err != nil {
+			fmt.Println("Connection error:", err)
+		}
+	}()
+
+	return c, &res.Result, nil
+}
+
+func convertAlgoSignResult(v []interface{}) ([]string, error) {
+	var res []string
+
+	if len(v) > 0 {
+		for _, item := range v {
+			switch item := item.(type) {
+			case string:
+				res = append(res, item)
+			default:
+				if r, ok := item.([]interface{}); ok {
+					if len(r) > 0 {
+						switch r[0].(type) {
+						case string:
+							for _, item := range r {
+								if s, ok := item.(string); ok {
+									res = append(res, s)
+								} else {
+									return nil, errors.New("failed to convert results to strings")
+								}
+							}
+						case float64:
+							bytes := make([]uint8, len(r))
+							for i, item := range r {
+								if s, ok := item.(float64); ok {
+									bytes[i] = uint8(s)
+								} else {
+									return nil, errors.New("failed to convert results to float64")
+								}
+							}
+							res = append(res, base64.StdEncoding.EncodeToString(bytes))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (c *Client) Sign(ctx context.Context, request AlgoSignRequest) (*AlgoSignResponse, error) {
+	id := c.id.Add(1)
+
+	req, err := MakeSignTransactions(id, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make send transactions request")
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	ch := make(chan AlgoSignResponse)
+
+	c.h.Push(id, func(r Incoming) error {
+		err := func() error {
+			var gr genericAlgoSignResponse
+
+			err := json.Unmarshal(r.Result, &gr)
+			if err != nil {
+				return errors.Wrap(err, "failed to unmarshal algo_signTxn response")
+			}
+
+			result, err := convertAlgoSignResult(gr.Result)
+			if err != nil {
+				return errors.Wrap(err, "failed to convert result")
+			}
+
+			resp := AlgoSignResponse{
+				Error:  gr.Error,
+				Result: result,
+			}
+
+			ch <- resp
+
+			return nil
+		}()
+
+		if err != nil {
+			cancel(err)
+			return err
+		}
+
+		return nil
+	})
+
+	var resp AlgoSignResponse
+
+	err = c.c.Send(c.topic, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send transactions request")
+	}
+
+	select {
+	case resp = <-ch:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,242 @@
+package wc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+type Conn struct {
+	host string
+	conn *websocket.Conn
+	key  []byte
+
+	debug bool
+}
+
+type ConnOption func(*Conn)
+
+func WithConnDebug(enabled bool) ConnOption {
+	return func(c *Conn) {
+		c.debug = enabled
+	}
+}
+
+func WithConnHost(host string) ConnOption {
+	return func(c *Conn) {
+		c.host = host
+	}
+}
+
+func WithConnKey(key []byte) ConnOption {
+	return func(c *Conn) {
+		c.key = key
+	}
+}
+
+func MakeTopic() string {
+	return uuid.NewString()
+}
+
+func MakeConn(opts ...ConnOption) (*Conn, error) {
+	header := make(http.Header)
+
+	c := &Conn{}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.host == "" {
+		rand.Seed(time.Now().UnixNano())
+
+		sub := letters[rand.Int()%len(letters)]
+		c.host = fmt.Sprintf("%c.bridge.walletconnect.org", sub)
+	}
+
+	if c.key == nil {
+		key, err := MakeKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make key")
+		}
+		c.key = key
+	}
+
+	host := fmt.Sprintf("wss://%s/", c.host)
+
+	if c.debug {
+		fmt.Println("connecting to bridge:", host)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(host, header)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dial")
+	}
+
+	c.conn = conn
+
+	return c, nil
+}
+
+func (c *Conn) writeJSON(v interface{}) error {
+	if c.debug {
+		bs, _ := json.Marshal(v)
+		fmt.Printf("Sending: %s\n", string(bs))
+	}
+
+	return c.conn.WriteJSON(v)
+}
+
+func (c *Conn) Subscribe(topic string) error {
+	if c.debug {
+		fmt.Println("subscribing topic:", topic)
+	}
+	err := c.writeJSON(Message{
+		Topic:   topic,
+		Type:    "sub",
+		Payload: "",
+		Silent:  false,
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+func (c *Conn) Send(topic string, v any) error {
+	pb, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to marhsal")
+	}
+
+	if c.debug {
+		fmt.Println("Send:", string(pb))
+	}
+
+	iv, err := MakeIV()
+	if err != nil {
+		return errors.Wrap(err, "failed to make iv")
+	}
+
+	emsg, err := encrypt(pb, c.key, iv)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt")
+	}
+
+	cb, err := json.Marshal(emsg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	bs := string(cb)
+
+	msg := Message{
+		Topic:   topic,
+		Type:    "pub",
+		Payload: bs,
+		Silent:  false,
+	}
+
+	err = c.writeJSON(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	return nil
+}
+
+func (c *Conn) MakeUri(topic string) Uri {
+	return MakeUri(topic, c.host, c.key)
+}
+
+// Close closes the connection to the bridge; a pending Read returns an error
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) Read() (Incoming, error) {
+	_, p, err := c.conn.ReadMessage()
+	if err != nil {
+		return Incoming{}, errors.Wrap(err, "failed to read message")
+	}
+
+	if c.debug {
+		fmt.Println("Read message:", string(p))
+	}
+
+	var m Message
+	err = json.Unmarshal(p, &m)
+	if err != nil {
+		return Incoming{}, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	block, err := aes.NewCipher(c.key)
+	if err != nil {
+		return Incoming{}, errors.Wrap(err, "failed to create cipher")
+	}
+
+	var e encrypted
+
+	err = json.Unmarshal([]byte(m.Payload), &e)
+	if err != nil {
+		return Incoming{}, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	iv, err := hex.DecodeString(e.Iv)
+	if err != nil {
+		return Incoming{}, errors.Wrap(err, "failed to decode")
+	}
+
+	dec := cipher.NewCBCDecrypter(block, iv)
+
+	ct, err := hex.DecodeString(e.Data)
+	if err != nil {
+		return Incoming{}, errors.Wrap(err, "failed to decode")
+	}
+
+	dec.CryptBlocks(ct, ct)
+
+	var pb []byte
+	if len(ct) > 0 {
+		paddingSize := int(ct[len(ct)-1])
+		pb = ct[0 : len(ct)-paddingSize]
+	}
+
+	var head Header
+
+	if c.debug {
+		fmt.Println("Read:", string(pb))
+	}
+
+	err = json.Unmarshal(pb, &head)
+	if err != nil {
+		return Incoming{}, errors.Wrap(err, "failed to unmarshal response")
+	}
+
+	if len(head.JsonRPC) > 0 { // Defly does not set the field for error responses
+		if head.JsonRPC != jsonRpc20 {
+			return Incoming{}, errors.New("unexpected jsonrpc version")
+		}
+	}
+
+	if c.debug {
+		fmt.Println("Id:", head.Id)
+	}
+
+	return Incoming{
+		Header:  head,
+		Message: m,
+		Result:  pb,
+	}, nil
+}
@@ -0,0 +1,23 @@
+module github.com/dragmz/wc
+
+go 1.20
+
+require (
+	github.com/algorand/go-algorand-sdk v1.24.0
+	github.com/dragmz/tqr v0.0.0-20221017230537-9456828a0212
+	github.com/google/uuid v1.3.0
+	github.com/gorilla/websocket v1.5.0
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.8.1
+	golang.org/x/crypto v0.6.0
+)
+
+require (
+	github.com/algorand/go-codec/codec v1.1.9 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/go-querystring v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/yeqown/go-qrcode/v2 v2.2.1 // indirect
+	github.com/yeqown/reedsolomon v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
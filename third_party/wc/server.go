@@ -0,0 +1,144 @@
+package wc
+
+import (
+	"encoding/json"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+)
+
+type Server struct {
+	c *Conn
+
+	dapp string
+
+	sk ed25519.PrivateKey
+
+	ma *crypto.MultisigAccount
+
+	s Signer
+
+	debug bool
+}
+
+type ServerOption func(s *Server)
+
+func WithServerDebug(debug bool) func(*Server) {
+	return func(s *Server) {
+		s.debug = debug
+	}
+}
+
+func MakeServer(uri Uri, signer Signer, opts ...ServerOption) (*Server, error) {
+	s := &Server{}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	conn, err := MakeConn(
+		WithConnDebug(s.debug),
+		WithConnKey(uri.Key),
+		WithConnHost(uri.Url.Host),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make connection")
+	}
+
+	err = conn.Subscribe(uri.Topic)
+	if err != nil {
+		return nil, errors.Wrap(err, "wallet failed to subscribe to topic")
+	}
+
+	s.c = conn
+	s.s = signer
+
+	return s, nil
+}
+
+func (s *Server) Close() error {
+	return s.c.conn.Close()
+}
+
+func (s *Server) Run() error {
+	for {
+		incoming, err := s.c.Read()
+		if err != nil {
+			return errors.Wrap(err, "wallet failed to read")
+		}
+
+		switch incoming.Method {
+		case "algo_signTxn":
+			var req AlgoSignRequest
+			err = json.Unmarshal(incoming.Result, &req)
+			if err != nil {
+				return err
+			}
+
+			if len(req.Params) == 0 {
+				continue
+			}
+
+			if s.s != nil {
+				resp, err := s.s.Sign(req)
+				if err != nil {
+					return errors.Wrap(err, "failed to sign request")
+				}
+
+				response := OutgoingResponse{
+					Header: MakeResponseHeader(incoming.Id),
+					Result: resp.Result,
+				}
+
+				err = s.c.Send(s.dapp, response)
+				if err != nil {
+					return errors.Wrap(err, "failed to send sign repsonse")
+				}
+			}
+
+		case "wc_sessionRequest":
+			var req SessionRequestRequest
+			err = json.Unmarshal(incoming.Result, &req)
+			if err != nil {
+				return err
+			}
+
+			peer := MakeTopic()
+
+			err = s.c.Subscribe(peer)
+			if err != nil {
+				return err
+			}
+
+			var addresses []string
+
+			if s.s != nil {
+				addresses = append(addresses, s.s.Address())
+			}
+
+			result := SessionRequestResponseResult{
+				PeerId: peer,
+				PeerMeta: SessionRequestPeerMeta{
+					Description: "Algorand Multisig Tools",
+					Url:         "https://github.com/dragmz/ams",
+					Name:        "AMS"},
+				Approved: true,
+				ChainId:  4160,
+				Accounts: addresses,
+			}
+
+			response := OutgoingResponse{
+				Header: MakeResponseHeader(incoming.Id),
+				Result: result,
+			}
+
+			s.dapp = req.Params[0].PeerId
+
+			err = s.c.Send(s.dapp, response)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
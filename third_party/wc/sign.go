@@ -0,0 +1,6 @@
+package wc
+
+type Signer interface {
+	Sign(AlgoSignRequest) (*AlgoSignResponse, error)
+	Address() string
+}
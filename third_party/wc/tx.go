@@ -0,0 +1,26 @@
+package wc
+
+import (
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+)
+
+func DecodeAlgoSignResponse(response AlgoSignResponse) ([][]byte, error) {
+	var results [][]byte
+
+	if response.Error != nil {
+		return nil, errors.Errorf("sign response error: %s, code: %d", response.Error.Message, response.Error.Code)
+	}
+
+	for _, res := range response.Result {
+		bs, err := base64.StdEncoding.DecodeString(res)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode transaction")
+		}
+
+		results = append(results, bs)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,32 @@
+package wc_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/dragmz/wc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeAlgoSignResponse(t *testing.T) {
+	addr := "AGEIPIQHKIYYBMP5O4OCXOJRQQ3BPDON64VRQ5SALEDU6HN6RGJNILNYBY"
+	tx, err := transaction.MakePaymentTxnWithFlatFee(addr, addr,
+		transaction.MinTxnFee, 0, 1000, 2000, []byte("test transaction"), "", "testid", []byte("testhash"))
+	assert.NoError(t, err)
+
+	be := msgpack.Encode(tx)
+	b64 := base64.StdEncoding.EncodeToString(be)
+	resp := wc.AlgoSignResponse{
+		Result: []string{b64},
+	}
+
+	raws, err := wc.DecodeAlgoSignResponse(resp)
+	assert.NoError(t, err)
+
+	assert.Len(t, raws, 1)
+	ba := raws[0]
+
+	assert.Equal(t, be, ba)
+}
@@ -0,0 +1,25 @@
+package wc_test
+
+import (
+	"testing"
+
+	"github.com/dragmz/wc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopic(t *testing.T) {
+	a := wc.MakeTopic()
+	assert.NotEmpty(t, a)
+
+	b := wc.MakeTopic()
+	assert.NotEmpty(t, b)
+
+	assert.NotEqual(t, a, b)
+}
+
+func TestUri(t *testing.T) {
+	u := wc.MakeUri("12345678-1234-1234-1234-123456789012", "a.bridge.walletconnect.org", []byte{1, 2, 3})
+	s := u.String()
+
+	assert.Equal(t, "wc:12345678-1234-1234-1234-123456789012@1?bridge=https%3A%2F%2Fa.bridge.walletconnect.org&key=010203", s)
+}
@@ -0,0 +1,252 @@
+package wc
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var letters = []rune("abcdefghijklmnopqrstuvwxyz")
+
+const (
+	jsonRpc20 = "2.0"
+
+	sessionRequestMethod = "wc_sessionRequest"
+	algoSignTxnMethod    = "algo_signTxn"
+)
+
+type Msg interface{}
+
+type WC struct {
+}
+
+type SessionRequestPeerMeta struct {
+	Description string   `json:"description"`
+	Url         string   `json:"url"`
+	Icons       []string `json:"icons"`
+	Name        string   `json:"name"`
+}
+
+type SessionRequestRequest struct {
+	Params []SessionRequestParams `json:"params"`
+}
+
+type SessionRequestParams struct {
+	PeerId   string                 `json:"peerId"`
+	PeerMeta SessionRequestPeerMeta `json:"peerMeta"`
+	ChainId  int                    `json:"chainId"`
+}
+
+type Message struct {
+	Topic   string `json:"topic"`
+	Type    string `json:"type"`
+	Payload string `json:"payload"`
+	Silent  bool   `json:"silent"`
+}
+
+type Header struct {
+	Id      uint64 `json:"id"`
+	JsonRPC string `json:"jsonrpc"`
+	Method  string `json:"method,omitempty"`
+	Error   *Error `json:"error,omitempty"`
+}
+
+type SessionUpdateParams struct {
+	Approved  bool     `json:"approved"`
+	ChainId   int      `json:"chainId"`
+	NetworkId int      `json:"networkId"`
+	Accounts  []string `json:"accounts"`
+}
+
+type SessionUpdateRequest struct {
+	Header
+	Params []SessionUpdateParams `json:"params"`
+}
+
+type Request struct {
+	Header
+	Params []interface{} `json:"params"`
+}
+
+type SessionRequestResponseResult struct {
+	PeerId   string                 `json:"peerId"`
+	PeerMeta SessionRequestPeerMeta `json:"peerMeta"`
+	Approved bool                   `json:"approved"`
+	ChainId  int                    `json:"chainId"`
+	Accounts []string               `json:"accounts"`
+}
+
+type OutgoingResponse struct {
+	Header
+	Result any `json:"result"`
+}
+
+type Incoming struct {
+	Header
+	Message Message
+	Result  []byte
+}
+
+type SessionRequestResponse struct {
+	Error  *Error                       `json:"error,omitempty"`
+	Result SessionRequestResponseResult `json:"result"`
+}
+
+type AlgoSignRequest struct {
+	Params [][]AlgoSignParams `json:"params"`
+}
+
+type AlgoSignParams struct {
+	TxnBase64 string   `json:"txn"`
+	AuthAddr  string   `json:"authAddr,omitempty"`
+	Message   string   `json:"message,omitempty"`
+	Signers   []string `json:"signers,omitempty"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("error: %s, code: %d", e.Message, e.Code)
+}
+
+type genericAlgoSignResponse struct {
+	Error  *Error        `json:"error,omitempty"`
+	Result []interface{} `json:"result"`
+}
+
+type AlgoSignResponse struct {
+	Error  *Error   `json:"error,omitempty"`
+	Result []string `json:"result"`
+}
+
+func MakeSignTransactions(id uint64, req AlgoSignRequest) (Request, error) {
+	ps := make([]interface{}, len(req.Params))
+	for i, p := range req.Params {
+		ps[i] = p
+	}
+
+	r := Request{
+		Header: MakeRequestHeader(id, algoSignTxnMethod),
+		Params: ps,
+	}
+
+	return r, nil
+}
+
+func MakeResponseHeader(id uint64) Header {
+	return Header{
+		Id:      id,
+		JsonRPC: jsonRpc20,
+	}
+}
+
+func MakeRequestHeader(id uint64, method string) Header {
+	return Header{
+		Id:      id,
+		JsonRPC: jsonRpc20,
+		Method:  method,
+	}
+}
+
+func MakeRequestSession(id uint64, peer string, meta SessionRequestPeerMeta) (Request, error) {
+	req := Request{
+		Header: MakeRequestHeader(id, sessionRequestMethod),
+		Params: []interface{}{
+			SessionRequestParams{
+				PeerId:   peer,
+				PeerMeta: meta,
+				ChainId:  4160,
+			},
+		},
+	}
+
+	return req, nil
+}
+
+type Uri struct {
+	Version int
+	Topic   string
+	Url     *url.URL
+	Key     []byte
+	Values  url.Values
+}
+
+func MakeUri(topic string, host string, key []byte) Uri {
+	return Uri{
+		Version: 1,
+		Topic:   topic,
+		Url:     &url.URL{Scheme: "https", Host: host},
+		Key:     key,
+	}
+}
+
+func (u Uri) String() string {
+	values := u.Values.Encode()
+	if len(values) > 0 {
+		values = fmt.Sprintf("&%s", values)
+	}
+	str := fmt.Sprintf("wc:%s@%d?bridge=https%%3A%%2F%%2F%s&key=%s%s", u.Topic, u.Version, u.Url.Host, hex.EncodeToString(u.Key), values)
+	return str
+}
+
+func ParseUri(v string) (*Uri, error) {
+	parts := strings.Split(v, "@")
+
+	if len(parts) != 2 {
+		return nil, errors.New("malformed uri")
+	}
+
+	a_parts := strings.Split(parts[0], ":")
+	if len(a_parts) != 2 {
+		return nil, errors.New("malformed uri")
+	}
+
+	if a_parts[0] != "wc" {
+		return nil, errors.New("malformed uri")
+	}
+
+	topic := a_parts[1]
+
+	b_parts := strings.Split(parts[1], "?")
+	if len(b_parts) != 2 {
+		return nil, errors.New("malformed uri")
+	}
+
+	version, err := strconv.Atoi(b_parts[0])
+	if err != nil {
+		return nil, errors.New("malformed uri")
+	}
+
+	q, err := url.ParseQuery(b_parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hex.DecodeString(q.Get("key"))
+	if err != nil {
+		return nil, err
+	}
+
+	bridge, err := url.Parse(q.Get("bridge"))
+	if err != nil {
+		return nil, err
+	}
+
+	q.Del("key")
+	q.Del("bridge")
+
+	return &Uri{
+		Version: version,
+		Topic:   topic,
+		Url:     bridge,
+		Key:     key,
+		Values:  q,
+	}, nil
+}
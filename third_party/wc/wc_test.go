@@ -0,0 +1,14 @@
+package wc
+
+import "testing"
+
+func TestParseUri(t *testing.T) {
+	u, err := ParseUri("wc:11112222-3333-4444-5555-666677778888@1?bridge=https%3A%2F%2Fj.bridge.walletconnect.org&key=9606059915cf2ea2ba85725e2edbb9c7f5a301c5cdf967a4c210716ed028fe8d&algorand=true")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if u.Topic != "11112222-3333-4444-5555-666677778888" {
+		t.Error()
+	}
+}
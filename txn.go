@@ -2,53 +2,344 @@ package ams
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/algorand/go-algorand-sdk/types"
 )
 
-func FormatTxn(txn types.Transaction) string {
+var onCompletionNames = map[types.OnCompletion]string{
+	types.NoOpOC:              "NoOp",
+	types.OptInOC:             "OptIn",
+	types.CloseOutOC:          "CloseOut",
+	types.ClearStateOC:        "ClearState",
+	types.UpdateApplicationOC: "UpdateApplication",
+	types.DeleteApplicationOC: "DeleteApplication",
+}
+
+func formatOnCompletion(oc types.OnCompletion) string {
+	if name, ok := onCompletionNames[oc]; ok {
+		return fmt.Sprintf("%s (%d)", name, oc)
+	}
+
+	return fmt.Sprintf("%d", oc)
+}
+
+// formatBytes shows printable data as a quoted string next to its hex form
+func formatBytes(bs []byte) string {
+	if len(bs) == 0 {
+		return "(empty)"
+	}
+
+	printable := utf8.Valid(bs)
+	if printable {
+		for _, r := range string(bs) {
+			if !unicode.IsPrint(r) {
+				printable = false
+				break
+			}
+		}
+	}
+
+	if printable {
+		return fmt.Sprintf("%q (0x%s)", bs, hex.EncodeToString(bs))
+	}
+
+	return fmt.Sprintf("0x%s", hex.EncodeToString(bs))
+}
+
+type formatOptions struct {
+	assets AssetResolver
+	abi    *ABIRegistry
+	risk   *RiskAnalyzer
+	book   *AddressBook
+	clock  *RoundClock
+}
+
+type FormatOption func(o *formatOptions)
+
+// WithFormatAssetResolver shows asset amounts in units of the resolved assets
+func WithFormatAssetResolver(r AssetResolver) FormatOption {
+	return func(o *formatOptions) {
+		o.assets = r
+	}
+}
+
+// WithFormatABIRegistry decodes ARC-4 application calls of the registered apps
+func WithFormatABIRegistry(r *ABIRegistry) FormatOption {
+	return func(o *formatOptions) {
+		o.abi = r
+	}
+}
+
+// WithFormatAddressBook labels the addresses and flags the ones resembling labeled addresses
+func WithFormatAddressBook(b *AddressBook) FormatOption {
+	return func(o *formatOptions) {
+		o.book = b
+	}
+}
+
+// WithFormatRoundClock shows the validity window in wall-clock time
+func WithFormatRoundClock(c *RoundClock) FormatOption {
+	return func(o *formatOptions) {
+		o.clock = c
+	}
+}
+
+// WithFormatRiskAnalyzer adds the risk findings to the decoded transactions
+func WithFormatRiskAnalyzer(a *RiskAnalyzer) FormatOption {
+	return func(o *formatOptions) {
+		o.risk = a
+	}
+}
+
+func decodeBase64(s string) []byte {
+	bs, _ := base64.StdEncoding.DecodeString(s)
+	return bs
+}
+
+func formatProgram(p *DecodedProgram) string {
+	return fmt.Sprintf("%d bytes, sha512_256: %s", p.Size, p.Hash)
+}
+
+func (d *DecodedTxn) formatRole(addr string) string {
+	if len(addr) == 0 {
+		return "(none)"
+	}
+
+	return d.addr(addr)
+}
+
+// addr returns the address followed by its address book label
+func (d *DecodedTxn) addr(addr string) string {
+	if l, ok := d.Labels[addr]; ok {
+		return fmt.Sprintf("%s %s", addr, l)
+	}
+
+	return addr
+}
+
+func (c *DecodedAppCall) text(out *strings.Builder, d *DecodedTxn) {
+	if c.AppID == 0 {
+		out.WriteString("Application ID: 0 (create)\n")
+	} else {
+		out.WriteString(fmt.Sprintf("Application ID: %d\n", c.AppID))
+	}
+
+	out.WriteString(fmt.Sprintf("On Complete: %s\n", c.OnCompletion))
+
+	switch c.OnCompletion {
+	case formatOnCompletion(types.UpdateApplicationOC):
+		out.WriteString("[!!!] APPLICATION UPDATE\n")
+	case formatOnCompletion(types.DeleteApplicationOC):
+		out.WriteString("[!!!] APPLICATION DELETE\n")
+	}
+
+	if c.ABI != nil {
+		out.WriteString(fmt.Sprintf("ABI method: %s (%s)\n", c.ABI.Method, c.ABI.Signature))
+
+		for i, arg := range c.ABI.Args {
+			name := arg.Name
+			if len(name) == 0 {
+				name = fmt.Sprintf("#%d", i)
+			}
+
+			out.WriteString(fmt.Sprintf("ABI arg %s (%s): %s\n", name, arg.Type, arg))
+		}
+	}
+
+	for i, arg := range c.Args {
+		out.WriteString(fmt.Sprintf("Arg #%d: %s\n", i, formatBytes(decodeBase64(arg))))
+	}
+
+	for i, acc := range c.Accounts {
+		out.WriteString(fmt.Sprintf("Foreign account #%d: %s\n", i+1, d.addr(acc)))
+	}
+
+	for i, app := range c.ForeignApps {
+		out.WriteString(fmt.Sprintf("Foreign app #%d: %d\n", i+1, app))
+	}
+
+	for i, asset := range c.ForeignAssets {
+		out.WriteString(fmt.Sprintf("Foreign asset #%d: %s\n", i, asset.Text))
+	}
+
+	for _, box := range c.Boxes {
+		app := "current app"
+		if box.App > 0 {
+			app = fmt.Sprintf("app %d", box.App)
+		} else if box.AppIndex > 0 {
+			app = fmt.Sprintf("foreign app #%d", box.AppIndex)
+		}
+
+		out.WriteString(fmt.Sprintf("Box: %s, name: %s\n", app, formatBytes(decodeBase64(box.Name))))
+	}
+
+	if c.Approval != nil {
+		out.WriteString(fmt.Sprintf("Approval program: %s\n", formatProgram(c.Approval)))
+	}
+
+	if c.Clear != nil {
+		out.WriteString(fmt.Sprintf("Clear program: %s\n", formatProgram(c.Clear)))
+	}
+
+	if c.GlobalSchema != nil {
+		out.WriteString(fmt.Sprintf("Global schema: %d uints, %d byte slices\n", c.GlobalSchema.NumUint, c.GlobalSchema.NumByteSlice))
+	}
+
+	if c.LocalSchema != nil {
+		out.WriteString(fmt.Sprintf("Local schema: %d uints, %d byte slices\n", c.LocalSchema.NumUint, c.LocalSchema.NumByteSlice))
+	}
+
+	if c.ExtraPages > 0 {
+		out.WriteString(fmt.Sprintf("Extra program pages: %d\n", c.ExtraPages))
+	}
+}
+
+func (c *DecodedAssetConfig) text(out *strings.Builder, d *DecodedTxn) {
+	switch c.Action {
+	case "create":
+		out.WriteString("Asset: create\n")
+	case "destroy":
+		out.WriteString(fmt.Sprintf("[!!!] ASSET DESTROY: %s\n", c.Asset.Text))
+		return
+	default:
+		out.WriteString(fmt.Sprintf("Asset: %s reconfigure\n", c.Asset.Text))
+	}
+
+	if p := c.Params; p != nil {
+		out.WriteString(fmt.Sprintf("Asset name: %q\n", p.AssetName))
+		out.WriteString(fmt.Sprintf("Unit name: %q\n", p.UnitName))
+		out.WriteString(fmt.Sprintf("Total: %d base units\n", p.Total))
+		out.WriteString(fmt.Sprintf("Decimals: %d\n", p.Decimals))
+		out.WriteString(fmt.Sprintf("Default frozen: %t\n", p.DefaultFrozen))
+
+		if len(p.URL) > 0 {
+			out.WriteString(fmt.Sprintf("URL: %q\n", p.URL))
+		}
+
+		if len(p.MetadataHash) > 0 {
+			out.WriteString(fmt.Sprintf("Metadata hash: %s\n", p.MetadataHash))
+		}
+	}
+
+	// a zero role address in a reconfiguration removes the role for good
+	out.WriteString(fmt.Sprintf("Manager: %s\n", d.formatRole(c.Manager)))
+	out.WriteString(fmt.Sprintf("Reserve: %s\n", d.formatRole(c.Reserve)))
+	out.WriteString(fmt.Sprintf("Freeze: %s\n", d.formatRole(c.Freeze)))
+	out.WriteString(fmt.Sprintf("Clawback: %s\n", d.formatRole(c.Clawback)))
+}
+
+func (k *DecodedKeyreg) text(out *strings.Builder) {
+	if k.Nonparticipation {
+		out.WriteString("[!] Marks the account as non-participating permanently\n")
+	}
+
+	if !k.Online {
+		out.WriteString("Key registration: offline\n")
+		return
+	}
+
+	out.WriteString("Key registration: online\n")
+	out.WriteString(fmt.Sprintf("Vote key: %s\n", k.VoteKey))
+	out.WriteString(fmt.Sprintf("Selection key: %s\n", k.SelectionKey))
+
+	if len(k.StateProofKey) > 0 {
+		out.WriteString(fmt.Sprintf("State proof key: %s\n", k.StateProofKey))
+	}
+
+	out.WriteString(fmt.Sprintf("Vote rounds: %d..%d\n", k.VoteFirst, k.VoteLast))
+	out.WriteString(fmt.Sprintf("Vote key dilution: %d\n", k.VoteKeyDilution))
+}
+
+// Text renders the transaction for manual review
+func (d *DecodedTxn) Text() string {
 	out := strings.Builder{}
 
-	out.WriteString(fmt.Sprintf("Type: %s\n", txn.Type))
-	out.WriteString(fmt.Sprintf("Sender: %s\n", txn.Sender))
+	out.WriteString(fmt.Sprintf("Type: %s\n", d.Type))
+	out.WriteString(fmt.Sprintf("Sender: %s\n", d.addr(d.Sender)))
 
-	switch txn.Type {
-	case types.PaymentTx:
-		out.WriteString(fmt.Sprintf("Receiver: %s\n", txn.Receiver))
-		out.WriteString(fmt.Sprintf("Amount: %d microALGO\n", txn.Amount))
-		if !txn.CloseRemainderTo.IsZero() {
-			out.WriteString(fmt.Sprintf("Close remainer to: %s\n", txn.CloseRemainderTo.String()))
+	if len(d.AuthAddr) > 0 {
+		out.WriteString(fmt.Sprintf("Auth address: %s\n", d.addr(d.AuthAddr)))
+	}
+
+	switch {
+	case d.Payment != nil:
+		p := d.Payment
+		out.WriteString(fmt.Sprintf("Receiver: %s\n", d.addr(p.Receiver)))
+		out.WriteString(fmt.Sprintf("Amount: %s\n", p.AmountText))
+		if len(p.CloseRemainderTo) > 0 {
+			out.WriteString(fmt.Sprintf("Close remainer to: %s\n", d.addr(p.CloseRemainderTo)))
 		}
 
-	case types.AssetTransferTx:
-		out.WriteString(fmt.Sprintf("Receiver: %s\n", txn.AssetReceiver))
-		out.WriteString(fmt.Sprintf("Amount: %d base units of ASA #%d\n", txn.AssetAmount, txn.XferAsset))
-		if !txn.AssetCloseTo.IsZero() {
-			out.WriteString(fmt.Sprintf("Close to: %s\n", txn.AssetCloseTo.String()))
+	case d.AssetTransfer != nil:
+		a := d.AssetTransfer
+		if len(a.ClawbackFrom) > 0 {
+			out.WriteString(fmt.Sprintf("[!] CLAWBACK FROM: %s\n", d.addr(a.ClawbackFrom)))
+		}
+		out.WriteString(fmt.Sprintf("Receiver: %s\n", d.addr(a.Receiver)))
+		out.WriteString(fmt.Sprintf("Amount: %s\n", a.AmountText))
+		if len(a.CloseTo) > 0 {
+			out.WriteString(fmt.Sprintf("Close to: %s\n", d.addr(a.CloseTo)))
 		}
+
+	case d.AssetConfig != nil:
+		d.AssetConfig.text(&out, d)
+
+	case d.AssetFreeze != nil:
+		f := d.AssetFreeze
+		out.WriteString(fmt.Sprintf("Asset: %s\n", f.Asset.Text))
+		out.WriteString(fmt.Sprintf("Target: %s\n", d.addr(f.Target)))
+		if f.Frozen {
+			out.WriteString("Action: freeze\n")
+		} else {
+			out.WriteString("Action: unfreeze\n")
+		}
+
+	case d.Keyreg != nil:
+		d.Keyreg.text(&out)
 	}
 
-	if len(txn.Group) == 0 {
-		out.WriteString(fmt.Sprintf("Group Id: %s\n", base64.StdEncoding.EncodeToString(txn.Group[:])))
+	if len(d.Group) > 0 {
+		out.WriteString(fmt.Sprintf("Group Id: %s\n", d.Group))
+	}
+	if d.LastValid < d.FirstValid {
+		out.WriteString(fmt.Sprintf("[!] Validity: %d..%d (INVERTED - never valid)\n", d.FirstValid, d.LastValid))
+	} else {
+		out.WriteString(fmt.Sprintf("Validity: %d..%d (%d rounds)\n", d.FirstValid, d.LastValid, d.ValidityRounds))
+	}
+	if d.Validity != nil {
+		out.WriteString(fmt.Sprintf("Validity status: %s\n", d.Validity))
+	}
+
+	if len(d.RekeyTo) > 0 {
+		out.WriteString(fmt.Sprintf("[!!!] REKEY TO: %s\n", d.addr(d.RekeyTo)))
 	}
-	out.WriteString(fmt.Sprintf("Validity: %d..%d (%d rounds)\n", txn.FirstValid, txn.LastValid, txn.LastValid-txn.FirstValid+1))
 
-	if !txn.RekeyTo.IsZero() {
-		out.WriteString(fmt.Sprintf("[!!!] REKEY TO: %s\n", txn.RekeyTo.String()))
+	if d.AppCall != nil {
+		d.AppCall.text(&out, d)
 	}
 
-	switch txn.Type {
-	case types.ApplicationCallTx:
-		out.WriteString(fmt.Sprintf("Application ID: %d\n", txn.ApplicationID))
-		out.WriteString(fmt.Sprintf("On Complete: %d\n", txn.OnCompletion))
+	out.WriteString(fmt.Sprintf("Fee: %s\n", d.FeeText))
+	if len(d.Lease) > 0 {
+		out.WriteString(fmt.Sprintf("Lease: %s\n", d.Lease))
+	}
+	if d.NoteDecoded != nil {
+		out.WriteString(d.NoteDecoded.String())
+		out.WriteString("\n")
 	}
 
-	out.WriteString(fmt.Sprintf("Fee: %d microALGO\n", txn.Fee))
-	if len(txn.Note) > 0 {
-		out.WriteString(fmt.Sprintf("Note: %s\n", txn.Note))
+	for _, a := range d.Annotations {
+		out.WriteString(fmt.Sprintf("[!] %s\n", a))
 	}
 
 	return out.String()
 }
+
+// FormatTxn renders the text view of the decoded transaction
+func FormatTxn(txn types.Transaction, opts ...FormatOption) string {
+	return DecodeTxn(txn, opts...).Text()
+}
@@ -0,0 +1,131 @@
+package ams
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTxnGroup(t *testing.T) {
+	acc := crypto.GenerateAccount()
+
+	txn := types.Transaction{
+		Type:   types.PaymentTx,
+		Header: types.Header{Sender: acc.Address, FirstValid: 1, LastValid: 10},
+	}
+
+	assert.NotContains(t, FormatTxn(txn), "Group Id")
+
+	txn.Group = types.Digest{1}
+	assert.Contains(t, FormatTxn(txn), "Group Id: AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+}
+
+func TestFormatTxnApplicationCall(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	txn := types.Transaction{
+		Type:   types.ApplicationCallTx,
+		Header: types.Header{Sender: acc.Address, FirstValid: 1, LastValid: 10, Lease: [32]byte{1}},
+		ApplicationFields: types.ApplicationFields{
+			ApplicationCallTxnFields: types.ApplicationCallTxnFields{
+				ApplicationID:     123,
+				OnCompletion:      types.UpdateApplicationOC,
+				ApplicationArgs:   [][]byte{[]byte("hello"), {0x00, 0xff}},
+				Accounts:          []types.Address{other.Address},
+				ForeignApps:       []types.AppIndex{456},
+				ForeignAssets:     []types.AssetIndex{789},
+				BoxReferences:     []types.BoxReference{{ForeignAppIdx: 1, Name: []byte("box")}},
+				ApprovalProgram:   []byte{0x06, 0x81, 0x01},
+				ClearStateProgram: []byte{0x06, 0x81, 0x01},
+				GlobalStateSchema: types.StateSchema{NumUint: 2, NumByteSlice: 1},
+				ExtraProgramPages: 1,
+			},
+		},
+	}
+
+	s := FormatTxn(txn)
+
+	assert.Contains(t, s, "On Complete: UpdateApplication (4)")
+	assert.Contains(t, s, "APPLICATION UPDATE")
+	assert.Contains(t, s, `Arg #0: "hello" (0x68656c6c6f)`)
+	assert.Contains(t, s, "Arg #1: 0x00ff")
+	assert.Contains(t, s, "Foreign account #1: "+other.Address.String())
+	assert.Contains(t, s, "Foreign app #1: 456")
+	assert.Contains(t, s, "Foreign asset #0: ASA #789")
+	assert.Contains(t, s, `Box: app 456, name: "box"`)
+	assert.Contains(t, s, "Approval program: 3 bytes, sha512_256: ")
+	assert.Contains(t, s, "Clear program: 3 bytes")
+	assert.Contains(t, s, "Global schema: 2 uints, 1 byte slices")
+	assert.Contains(t, s, "Extra program pages: 1")
+	assert.Contains(t, s, "Lease: AQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+}
+
+func TestFormatTxnAssetAndKeyreg(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	header := types.Header{Sender: acc.Address, FirstValid: 1, LastValid: 10}
+
+	s := FormatTxn(types.Transaction{
+		Type:   types.AssetConfigTx,
+		Header: header,
+		AssetConfigTxnFields: types.AssetConfigTxnFields{
+			AssetParams: types.AssetParams{Total: 1000, Decimals: 2, UnitName: "TST", AssetName: "Test", Manager: acc.Address},
+		},
+	})
+	assert.Contains(t, s, "Asset: create")
+	assert.Contains(t, s, `Unit name: "TST"`)
+	assert.Contains(t, s, "Manager: "+acc.Address.String())
+	assert.Contains(t, s, "Clawback: (none)")
+
+	s = FormatTxn(types.Transaction{
+		Type:                 types.AssetConfigTx,
+		Header:               header,
+		AssetConfigTxnFields: types.AssetConfigTxnFields{ConfigAsset: 5},
+	})
+	assert.Contains(t, s, "ASSET DESTROY: ASA #5")
+
+	s = FormatTxn(types.Transaction{
+		Type:   types.AssetFreezeTx,
+		Header: header,
+		AssetFreezeTxnFields: types.AssetFreezeTxnFields{
+			FreezeAccount: other.Address,
+			FreezeAsset:   5,
+			AssetFrozen:   true,
+		},
+	})
+	assert.Contains(t, s, "Target: "+other.Address.String())
+	assert.Contains(t, s, "Action: freeze")
+
+	s = FormatTxn(types.Transaction{
+		Type:   types.AssetTransferTx,
+		Header: header,
+		AssetTransferTxnFields: types.AssetTransferTxnFields{
+			XferAsset:     5,
+			AssetSender:   other.Address,
+			AssetReceiver: acc.Address,
+		},
+	})
+	assert.Contains(t, s, "CLAWBACK FROM: "+other.Address.String())
+
+	s = FormatTxn(types.Transaction{
+		Type:   types.KeyRegistrationTx,
+		Header: header,
+		KeyregTxnFields: types.KeyregTxnFields{
+			VotePK:          types.VotePK{1},
+			SelectionPK:     types.VRFPK{2},
+			VoteFirst:       100,
+			VoteLast:        200,
+			VoteKeyDilution: 10,
+		},
+	})
+	assert.Contains(t, s, "Key registration: online")
+	assert.Contains(t, s, "Vote rounds: 100..200")
+	assert.Contains(t, s, "Vote key dilution: 10")
+
+	s = FormatTxn(types.Transaction{Type: types.KeyRegistrationTx, Header: header})
+	assert.Contains(t, s, "Key registration: offline")
+}
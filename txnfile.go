@@ -0,0 +1,320 @@
+package ams
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	algojson "github.com/algorand/go-algorand-sdk/encoding/json"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+type TxnFormat string
+
+const (
+	TxnFormatMsgpack TxnFormat = "msgpack"
+	TxnFormatBase64  TxnFormat = "base64"
+	TxnFormatBase32  TxnFormat = "base32"
+	TxnFormatJson    TxnFormat = "json"
+)
+
+// InputTxn is a transaction decoded from an input together with its location
+type InputTxn struct {
+	Stxn   types.SignedTxn
+	Format TxnFormat
+	Source string
+	// Line is the 1-based line of text formats, 0 otherwise
+	Line int
+	// Offset is the byte offset in the msgpack data or the index in a json array
+	Offset int
+}
+
+func (t InputTxn) Location() string {
+	switch {
+	case t.Format == TxnFormatJson:
+		return fmt.Sprintf("%s[%d]", t.Source, t.Offset)
+	case t.Line > 0:
+		return fmt.Sprintf("%s:%d@%d", t.Source, t.Line, t.Offset)
+	default:
+		return fmt.Sprintf("%s@%d", t.Source, t.Offset)
+	}
+}
+
+// InputSignedTxns returns the signed transactions of the decoded inputs
+func InputSignedTxns(txs []InputTxn) []types.SignedTxn {
+	res := make([]types.SignedTxn, len(txs))
+	for i, tx := range txs {
+		res[i] = tx.Stxn
+	}
+	return res
+}
+
+// DetectTxnFormat guesses the format of transactions data
+func DetectTxnFormat(bs []byte) TxnFormat {
+	trimmed := bytes.TrimSpace(bs)
+
+	if len(trimmed) == 0 {
+		return TxnFormatMsgpack
+	}
+
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		return TxnFormatJson
+	}
+
+	text := true
+	for _, b := range trimmed {
+		isB64 := b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '+' || b == '/' || b == '='
+		if !isB64 && b != '\n' && b != '\r' && b != ' ' && b != '\t' {
+			text = false
+			break
+		}
+	}
+
+	if text {
+		return TxnFormatBase64
+	}
+
+	return TxnFormatMsgpack
+}
+
+// ReadTxnFile reads all the transactions from a file detecting its format
+func ReadTxnFile(path string) ([]InputTxn, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read transactions file")
+	}
+
+	return DecodeTxns(bs, path)
+}
+
+// DecodeTxns decodes concatenated msgpack, base64 or base32 text (one encoded blob per line) or goal compatible json
+func DecodeTxns(bs []byte, source string) ([]InputTxn, error) {
+	var res []InputTxn
+	var err error
+
+	switch DetectTxnFormat(bs) {
+	case TxnFormatJson:
+		res, err = decodeJsonTxns(bs, source)
+	case TxnFormatBase64:
+		res, err = decodeTextTxns(bs, source)
+	default:
+		res, err = decodeMsgpackTxns(bs, source, TxnFormatMsgpack, 0)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, errors.Errorf("no transactions in: %s", source)
+	}
+
+	return res, nil
+}
+
+func decodeMsgpackTxns(bs []byte, source string, format TxnFormat, line int) ([]InputTxn, error) {
+	var res []InputTxn
+
+	for off := 0; off < len(bs); {
+		var stx types.SignedTxn
+
+		dec := msgpack.NewDecoder(bytes.NewReader(bs[off:]))
+		err := dec.Decode(&stx)
+		n := dec.NumBytesRead()
+
+		if err != nil {
+			// bare transactions are accepted as unsigned ones
+			var txn types.Transaction
+
+			tdec := msgpack.NewDecoder(bytes.NewReader(bs[off:]))
+			terr := tdec.Decode(&txn)
+			if terr != nil {
+				return nil, errors.Wrapf(err, "failed to decode transaction msgpack - source: %s, line: %d, offset: %d", source, line, off)
+			}
+
+			stx = types.SignedTxn{Txn: txn}
+			n = tdec.NumBytesRead()
+		}
+
+		if n <= 0 {
+			return nil, errors.Errorf("failed to decode transaction msgpack - source: %s, line: %d, offset: %d", source, line, off)
+		}
+
+		res = append(res, InputTxn{
+			Stxn:   stx,
+			Format: format,
+			Source: source,
+			Line:   line,
+			Offset: off,
+		})
+
+		off += n
+	}
+
+	return res, nil
+}
+
+// decodeTextTxn decodes a line of base64 text falling back to base32 as both alphabets overlap
+func decodeTextTxn(text string, source string, line int) ([]InputTxn, error) {
+	raw, err := base64.StdEncoding.DecodeString(text)
+	if err == nil {
+		txs, err := decodeMsgpackTxns(raw, source, TxnFormatBase64, line)
+		if err == nil {
+			return txs, nil
+		}
+	}
+
+	raw, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(text, "="))
+	if err != nil {
+		return nil, errors.Errorf("failed to decode transaction text - expected base64 or base32, source: %s, line: %d", source, line)
+	}
+
+	return decodeMsgpackTxns(raw, source, TxnFormatBase32, line)
+}
+
+func decodeTextTxns(bs []byte, source string) ([]InputTxn, error) {
+	var res []InputTxn
+
+	sc := bufio.NewScanner(bytes.NewReader(bs))
+	sc.Buffer(nil, len(bs)+1)
+
+	var line int
+	for sc.Scan() {
+		line++
+
+		text := strings.TrimSpace(sc.Text())
+		if len(text) == 0 {
+			continue
+		}
+
+		txs, err := decodeTextTxn(text, source, line)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, txs...)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read transaction text")
+	}
+
+	return res, nil
+}
+
+// goal encodes addresses as base32 strings while the sdk codec expects raw bytes
+var jsonAddressKeys = map[string]bool{
+	"snd": true, "rcv": true, "close": true, "aclose": true, "arcv": true, "asnd": true,
+	"fadd": true, "rekey": true, "sgnr": true, "m": true, "r": true, "f": true, "c": true,
+	"apat": true,
+}
+
+func convertJsonAddresses(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if jsonAddressKeys[k] {
+				v[k] = convertJsonAddress(item)
+			} else {
+				v[k] = convertJsonAddresses(item)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertJsonAddresses(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func convertJsonAddress(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		addr, err := types.DecodeAddress(v)
+		if err != nil {
+			return v
+		}
+		return base64.StdEncoding.EncodeToString(addr[:])
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertJsonAddress(item)
+		}
+		return v
+	default:
+		return convertJsonAddresses(v)
+	}
+}
+
+func decodeJsonTxn(item interface{}) (types.SignedTxn, error) {
+	var stx types.SignedTxn
+
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return stx, errors.New("expected json object")
+	}
+
+	// bare transactions are accepted as unsigned ones
+	if _, ok := m["txn"]; !ok {
+		m = map[string]interface{}{
+			"txn": m,
+		}
+	}
+
+	bs, err := json.Marshal(convertJsonAddresses(m))
+	if err != nil {
+		return stx, errors.Wrap(err, "failed to encode transaction json")
+	}
+
+	err = algojson.LenientDecode(bs, &stx)
+	if err != nil {
+		return stx, errors.Wrap(err, "failed to decode transaction json")
+	}
+
+	return stx, nil
+}
+
+func decodeJsonTxns(bs []byte, source string) ([]InputTxn, error) {
+	var v interface{}
+
+	// numbers are kept as json.Number so that uint64 amounts do not lose precision
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+
+	err := dec.Decode(&v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode json - source: %s", source)
+	}
+
+	items, ok := v.([]interface{})
+	if !ok {
+		items = []interface{}{v}
+	}
+
+	var res []InputTxn
+
+	for i, item := range items {
+		stx, err := decodeJsonTxn(item)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode json transaction - source: %s, index: %d", source, i)
+		}
+
+		res = append(res, InputTxn{
+			Stxn:   stx,
+			Format: TxnFormatJson,
+			Source: source,
+			Offset: i,
+		})
+	}
+
+	return res, nil
+}
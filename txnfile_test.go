@@ -0,0 +1,82 @@
+package ams
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeTxnsMsgpackStream(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	stxs := makeTestPayments(t, acc, 2)
+
+	_, signed, err := crypto.SignTransaction(acc.PrivateKey, stxs[0].Txn)
+	assert.NoError(t, err)
+
+	// signed transaction followed by a bare one
+	bs := append(signed, msgpack.Encode(stxs[1].Txn)...)
+
+	res, err := DecodeTxns(bs, "test.stxn")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	assert.Equal(t, TxnFormatMsgpack, res[0].Format)
+	assert.Equal(t, 0, res[0].Offset)
+	assert.True(t, IsSigned(res[0].Stxn))
+
+	assert.Equal(t, len(signed), res[1].Offset)
+	assert.False(t, IsSigned(res[1].Stxn))
+	assert.Equal(t, stxs[1].Txn, res[1].Stxn.Txn)
+	assert.Equal(t, fmt.Sprintf("test.stxn@%d", len(signed)), res[1].Location())
+}
+
+func TestDecodeTxnsText(t *testing.T) {
+	stxs := makeTestPayments(t, crypto.GenerateAccount(), 2)
+
+	b64 := base64.StdEncoding.EncodeToString(msgpack.Encode(types.SignedTxn{Txn: stxs[0].Txn}))
+	b32 := base32.StdEncoding.EncodeToString(msgpack.Encode(types.SignedTxn{Txn: stxs[1].Txn}))
+
+	res, err := DecodeTxns([]byte(b64+"\n\n"+b32+"\n"), "test.txt")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	assert.Equal(t, TxnFormatBase64, res[0].Format)
+	assert.Equal(t, 1, res[0].Line)
+	assert.Equal(t, stxs[0].Txn, res[0].Stxn.Txn)
+
+	assert.Equal(t, TxnFormatBase32, res[1].Format)
+	assert.Equal(t, 3, res[1].Line)
+	assert.Equal(t, stxs[1].Txn, res[1].Stxn.Txn)
+}
+
+func TestDecodeTxnsGoalJson(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	stxs := makeTestPayments(t, acc, 2)
+
+	gh := base64.StdEncoding.EncodeToString(stxs[0].Txn.GenesisHash[:])
+
+	data := fmt.Sprintf(`[
+  {"txn": {"amt": 1, "fee": 1000, "fv": 1000, "gen": "test", "gh": "%s", "lv": 2000, "note": "dGVzdA==", "rcv": "%s", "snd": "%s", "type": "pay"}},
+  {"amt": 18446744073709551615, "fee": 1000, "fv": 1000, "gen": "test", "gh": "%s", "lv": 2000, "rcv": "%s", "snd": "%s", "type": "pay"}
+]`, gh, acc.Address, acc.Address, gh, acc.Address, acc.Address)
+
+	res, err := DecodeTxns([]byte(data), "test.json")
+	assert.NoError(t, err)
+	assert.Len(t, res, 2)
+
+	assert.Equal(t, TxnFormatJson, res[0].Format)
+	assert.Equal(t, acc.Address, res[0].Stxn.Txn.Receiver)
+	assert.Equal(t, types.MicroAlgos(1000), res[0].Stxn.Txn.Fee)
+	assert.Equal(t, stxs[0].Txn.GenesisHash, res[0].Stxn.Txn.GenesisHash)
+	assert.Equal(t, []byte("test"), res[0].Stxn.Txn.Note)
+
+	assert.Equal(t, acc.Address, res[1].Stxn.Txn.Sender)
+	assert.Equal(t, types.MicroAlgos(18446744073709551615), res[1].Stxn.Txn.Amount)
+	assert.Equal(t, "test.json[1]", res[1].Location())
+}
@@ -0,0 +1,233 @@
+package ams
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/wc"
+	"github.com/pkg/errors"
+)
+
+// DecodeSignedTxns decodes a stream of concatenated msgpack signed transactions
+func DecodeSignedTxns(bs []byte) ([]types.SignedTxn, error) {
+	var stxs []types.SignedTxn
+
+	dec := msgpack.NewDecoder(bytes.NewReader(bs))
+
+	for {
+		var stx types.SignedTxn
+		err := dec.Decode(&stx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode transaction msgpack - index: %d", len(stxs))
+		}
+
+		stxs = append(stxs, stx)
+	}
+
+	if len(stxs) == 0 {
+		return nil, errors.New("no transactions")
+	}
+
+	return stxs, nil
+}
+
+// EncodeSignedTxns encodes signed transactions as a concatenated msgpack stream
+func EncodeSignedTxns(stxs []types.SignedTxn) []byte {
+	var res []byte
+
+	for _, stx := range stxs {
+		res = append(res, msgpack.Encode(stx)...)
+	}
+
+	return res
+}
+
+// IsSigned returns true if the transaction carries any signature
+func IsSigned(stx types.SignedTxn) bool {
+	return stx.Sig != (types.Signature{}) || !stx.Msig.Blank() || !stx.Lsig.Blank()
+}
+
+// IsFullySigned returns true if the transaction has a signature or enough multisig subsigs to be submitted
+func IsFullySigned(stx types.SignedTxn) bool {
+	if stx.Sig != (types.Signature{}) || !stx.Lsig.Blank() {
+		return true
+	}
+
+	if stx.Msig.Blank() {
+		return false
+	}
+
+	var count int
+	for _, ss := range stx.Msig.Subsigs {
+		if ss.Sig != (types.Signature{}) {
+			count++
+		}
+	}
+
+	return count >= int(stx.Msig.Threshold)
+}
+
+// MergeSignedTxn adds the signatures of a newly signed transaction to the existing one.
+// Multisig subsigs are merged, an existing single signature or logic signature is kept as is.
+func MergeSignedTxn(existing types.SignedTxn, signed []byte) ([]byte, error) {
+	if len(signed) == 0 {
+		return msgpack.Encode(existing), nil
+	}
+
+	if !IsSigned(existing) {
+		return signed, nil
+	}
+
+	if existing.Msig.Blank() {
+		return msgpack.Encode(existing), nil
+	}
+
+	ma, err := crypto.MultisigAccountFromSig(existing.Msig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get multisig account from signature")
+	}
+
+	mstx, err := ConvertToMultisig(signed, ma)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert to multisig")
+	}
+
+	var stx types.SignedTxn
+	err = msgpack.Decode(mstx, &stx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode signed transaction msgpack")
+	}
+
+	if crypto.TransactionIDString(stx.Txn) != crypto.TransactionIDString(existing.Txn) {
+		return nil, errors.New("signed transaction does not match the existing one")
+	}
+
+	stx.AuthAddr = existing.AuthAddr
+
+	_, merged, err := crypto.MergeMultisigTransactions(msgpack.Encode(existing), msgpack.Encode(stx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge multisig transactions")
+	}
+
+	return merged, nil
+}
+
+// MakeSignRequest builds a sign request for the transactions of a group
+func MakeSignRequest(txs []types.Transaction) wc.AlgoSignRequest {
+	req := wc.AlgoSignRequest{
+		Params: [][]wc.AlgoSignParams{
+			{},
+		},
+	}
+
+	for _, txn := range txs {
+		b64 := base64.StdEncoding.EncodeToString(msgpack.Encode(txn))
+		req.Params[0] = append(req.Params[0], wc.AlgoSignParams{
+			TxnBase64: b64,
+		})
+	}
+
+	return req
+}
+
+// DecodeSignRequest returns the transactions of the first group of the request
+func DecodeSignRequest(req wc.AlgoSignRequest) ([]types.Transaction, error) {
+	if len(req.Params) == 0 {
+		return nil, nil
+	}
+
+	var txs []types.Transaction
+
+	for i, item := range req.Params[0] {
+		bs, err := base64.StdEncoding.DecodeString(item.TxnBase64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode base64 transaction data - index: %d", i)
+		}
+
+		var txn types.Transaction
+		err = msgpack.Decode(bs, &txn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode transaction msgpack - index: %d", i)
+		}
+
+		txs = append(txs, txn)
+	}
+
+	return txs, nil
+}
+
+// PartialSigner is implemented by signers that can skip the signatures already present in the transactions
+type PartialSigner interface {
+	SignPartial(req wc.AlgoSignRequest, existing []types.SignedTxn) (*wc.AlgoSignResponse, error)
+}
+
+// SignMissing asks the signer to sign the group and merges the results with the signatures already present.
+// Fully signed transactions are kept untouched; the signer is not called if nothing is missing.
+func SignMissing(s Signer, stxs []types.SignedTxn) ([][]byte, error) {
+	res := make([][]byte, len(stxs))
+
+	var missing bool
+	txs := make([]types.Transaction, len(stxs))
+
+	for i, stx := range stxs {
+		txs[i] = stx.Txn
+
+		if !IsFullySigned(stx) {
+			missing = true
+		}
+	}
+
+	var signed [][]byte
+
+	if missing {
+		var resp *wc.AlgoSignResponse
+		var err error
+
+		req := MakeSignRequest(txs)
+
+		if ps, ok := s.(PartialSigner); ok {
+			resp, err = ps.SignPartial(req, stxs)
+		} else {
+			resp, err = s.Sign(req)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to sign transactions")
+		}
+
+		if resp.Error != nil {
+			return nil, errors.Wrap(resp.Error, "sign request rejected")
+		}
+
+		signed, err = wc.DecodeAlgoSignResponse(*resp)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode sign response")
+		}
+
+		if len(signed) != len(stxs) {
+			return nil, errors.Errorf("invalid number of signed transactions - got: %d, expected: %d", len(signed), len(stxs))
+		}
+	}
+
+	for i, stx := range stxs {
+		if !missing || IsFullySigned(stx) {
+			res[i] = msgpack.Encode(stx)
+			continue
+		}
+
+		bs, err := MergeSignedTxn(stx, signed[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to merge signed transaction - index: %d", i)
+		}
+
+		res[i] = bs
+	}
+
+	return res, nil
+}
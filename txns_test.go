@@ -0,0 +1,107 @@
+package ams
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/dragmz/wc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeSignedTxnsConcatenated(t *testing.T) {
+	acc := crypto.GenerateAccount()
+
+	tx1, err := transaction.MakePaymentTxn(acc.Address.String(), acc.Address.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+	tx2, err := transaction.MakePaymentTxn(acc.Address.String(), acc.Address.String(), 1000, 2, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	bs := EncodeSignedTxns([]types.SignedTxn{{Txn: tx1}, {Txn: tx2}})
+
+	stxs, err := DecodeSignedTxns(bs)
+	assert.NoError(t, err)
+	assert.Len(t, stxs, 2)
+	assert.Equal(t, uint64(2), uint64(stxs[1].Txn.Amount))
+}
+
+func TestSignMissingMultisigPartial(t *testing.T) {
+	acc1 := crypto.GenerateAccount()
+	acc2 := crypto.GenerateAccount()
+	acc3 := crypto.GenerateAccount()
+
+	ma, err := crypto.MultisigAccountWithParams(1, 2, []types.Address{acc1.Address, acc2.Address, acc3.Address})
+	assert.NoError(t, err)
+
+	maddr, err := ma.Address()
+	assert.NoError(t, err)
+
+	tx, err := transaction.MakePaymentTxn(maddr.String(), maddr.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	s1, err := MakeLocalSigner(maddr.String(), acc1.PrivateKey, WithLocalSignerMultisigAccount(&ma))
+	assert.NoError(t, err)
+	s2, err := MakeLocalSigner(maddr.String(), acc2.PrivateKey, WithLocalSignerMultisigAccount(&ma))
+	assert.NoError(t, err)
+
+	res, err := SignMissing(s1, []types.SignedTxn{{Txn: tx}})
+	assert.NoError(t, err)
+
+	var partial types.SignedTxn
+	err = msgpack.Decode(res[0], &partial)
+	assert.NoError(t, err)
+	assert.False(t, IsFullySigned(partial))
+
+	res, err = SignMissing(s2, []types.SignedTxn{partial})
+	assert.NoError(t, err)
+
+	var full types.SignedTxn
+	err = msgpack.Decode(res[0], &full)
+	assert.NoError(t, err)
+	assert.True(t, IsFullySigned(full))
+	assert.True(t, crypto.VerifyMultisig(maddr, append([]byte("TX"), msgpack.Encode(tx)...), full.Msig))
+}
+
+func TestSignMissingKeepsFullySigned(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	other := crypto.GenerateAccount()
+
+	tx, err := transaction.MakePaymentTxn(acc.Address.String(), acc.Address.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	_, bs, err := crypto.SignTransaction(acc.PrivateKey, tx)
+	assert.NoError(t, err)
+
+	var stx types.SignedTxn
+	err = msgpack.Decode(bs, &stx)
+	assert.NoError(t, err)
+
+	s, err := MakeLocalSigner(other.Address.String(), other.PrivateKey)
+	assert.NoError(t, err)
+
+	res, err := SignMissing(s, []types.SignedTxn{stx})
+	assert.NoError(t, err)
+	assert.Equal(t, bs, res[0])
+}
+
+type rejectingSigner struct{}
+
+func (rejectingSigner) Sign(wc.AlgoSignRequest) (*wc.AlgoSignResponse, error) {
+	return MakeRejectedSignResponse("Rejected by the user"), nil
+}
+
+func (rejectingSigner) Address() string {
+	return ""
+}
+
+func TestSignMissingRejected(t *testing.T) {
+	acc := crypto.GenerateAccount()
+
+	tx, err := transaction.MakePaymentTxn(acc.Address.String(), acc.Address.String(), 1000, 1, 1000, 2000, nil, "", "test", []byte("test"))
+	assert.NoError(t, err)
+
+	_, err = SignMissing(rejectingSigner{}, []types.SignedTxn{{Txn: tx}})
+	assert.ErrorContains(t, err, "rejected")
+}
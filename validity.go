@@ -0,0 +1,167 @@
+package ams
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/pkg/errors"
+)
+
+// DefaultBlockTimeSamples is the number of recent blocks the average block time is measured over
+const DefaultBlockTimeSamples = 20
+
+// DefaultBlockTime is used when there are not enough blocks to measure the block time
+const DefaultBlockTime = 2800 * time.Millisecond
+
+// RoundClock converts rounds to wall-clock time using the last round time and the average block time
+type RoundClock struct {
+	Round     uint64
+	Time      time.Time
+	BlockTime time.Duration
+}
+
+type blockHeader struct {
+	Block struct {
+		TimeStamp int64 `codec:"ts"`
+	} `codec:"block"`
+}
+
+func blockTimestamp(ac *algod.Client, round uint64) (int64, error) {
+	bs, err := ac.BlockRaw(round).Do(context.Background())
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get block: %d", round)
+	}
+
+	var h blockHeader
+	err = msgpack.NewLenientDecoder(bytes.NewReader(bs)).Decode(&h)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to decode block: %d", round)
+	}
+
+	return h.Block.TimeStamp, nil
+}
+
+// FetchRoundClock reads the last round from algod and measures the average time of the recent blocks
+func FetchRoundClock(ac *algod.Client, samples uint64) (*RoundClock, error) {
+	status, err := ac.Status().Do(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get algod status")
+	}
+
+	c := &RoundClock{
+		Round:     status.LastRound,
+		Time:      time.Now().Add(-time.Duration(status.TimeSinceLastRound)),
+		BlockTime: DefaultBlockTime,
+	}
+
+	if samples == 0 || status.LastRound <= samples {
+		return c, nil
+	}
+
+	last, err := blockTimestamp(ac, status.LastRound)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := blockTimestamp(ac, status.LastRound-samples)
+	if err != nil {
+		return nil, err
+	}
+
+	if last > first {
+		c.BlockTime = time.Duration(last-first) * time.Second / time.Duration(samples)
+	}
+
+	return c, nil
+}
+
+// CurrentRound estimates the last round at the given time
+func (c *RoundClock) CurrentRound(now time.Time) uint64 {
+	elapsed := now.Sub(c.Time)
+	if elapsed <= 0 || c.BlockTime <= 0 {
+		return c.Round
+	}
+
+	return c.Round + uint64(elapsed/c.BlockTime)
+}
+
+// RoundTime estimates when the round is or was committed
+func (c *RoundClock) RoundTime(round uint64) time.Time {
+	return c.Time.Add(time.Duration(int64(round)-int64(c.Round)) * c.BlockTime)
+}
+
+// ValidityEstimate describes a validity window in wall-clock time
+type ValidityEstimate struct {
+	CurrentRound uint64 `json:"current-round" yaml:"current-round"`
+	Started      bool   `json:"started" yaml:"started"`
+	Expired      bool   `json:"expired" yaml:"expired"`
+	// Start is the time the first valid round can be proposed
+	Start time.Time `json:"start" yaml:"start"`
+	// End is the time the last valid round is committed
+	End time.Time `json:"end" yaml:"end"`
+
+	now time.Time
+}
+
+// Estimate places the first..last window relative to the current round
+func (c *RoundClock) Estimate(first uint64, last uint64, now time.Time) ValidityEstimate {
+	current := c.CurrentRound(now)
+
+	e := ValidityEstimate{
+		CurrentRound: current,
+		Started:      current+1 >= first,
+		Expired:      current >= last,
+		End:          c.RoundTime(last),
+		now:          now,
+	}
+
+	if first > 0 {
+		e.Start = c.RoundTime(first - 1)
+	} else {
+		e.Start = c.RoundTime(0)
+	}
+
+	return e
+}
+
+// Remaining is the time left until the window closes
+func (e ValidityEstimate) Remaining() time.Duration {
+	if e.Expired {
+		return 0
+	}
+
+	return e.End.Sub(e.now)
+}
+
+func formatClockTime(t time.Time) string {
+	return t.Local().Format("2006-01-02 15:04:05 MST")
+}
+
+func formatApprox(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	return "~" + d.Round(time.Second).String()
+}
+
+func (e ValidityEstimate) String() string {
+	now := e.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	switch {
+	case e.Expired:
+		return fmt.Sprintf("current round %d, [!] EXPIRED %s ago (%s)", e.CurrentRound, formatApprox(now.Sub(e.End)), formatClockTime(e.End))
+	case !e.Started:
+		return fmt.Sprintf("current round %d, not valid yet, starts in %s (%s), closes in %s (%s)",
+			e.CurrentRound, formatApprox(e.Start.Sub(now)), formatClockTime(e.Start), formatApprox(e.End.Sub(now)), formatClockTime(e.End))
+	default:
+		return fmt.Sprintf("current round %d, valid now, closes in %s (%s)", e.CurrentRound, formatApprox(e.End.Sub(now)), formatClockTime(e.End))
+	}
+}
@@ -0,0 +1,52 @@
+package ams
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchRoundClock(t *testing.T) {
+	ac, _ := makeTestAlgod(t, map[string]http.HandlerFunc{
+		"/v2/status":      jsonHandler(map[string]interface{}{"last-round": 1000, "time-since-last-round": uint64(time.Second)}),
+		"/v2/blocks/1000": msgpackHandler(map[string]interface{}{"block": map[string]interface{}{"ts": 1060, "rnd": 1000}}),
+		"/v2/blocks/980":  msgpackHandler(map[string]interface{}{"block": map[string]interface{}{"ts": 1000, "rnd": 980}}),
+	})
+
+	c, err := FetchRoundClock(ac, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), c.Round)
+	assert.Equal(t, 3*time.Second, c.BlockTime)
+}
+
+func TestRoundClockEstimate(t *testing.T) {
+	now := time.Now()
+	c := &RoundClock{Round: 1000, Time: now, BlockTime: 3 * time.Second}
+
+	e := c.Estimate(990, 1100, now)
+	assert.True(t, e.Started)
+	assert.False(t, e.Expired)
+	assert.Equal(t, 300*time.Second, e.Remaining())
+	assert.Contains(t, e.String(), "valid now, closes in ~5m0s")
+
+	e = c.Estimate(1011, 1100, now)
+	assert.False(t, e.Started)
+	assert.Contains(t, e.String(), "starts in ~30s")
+
+	e = c.Estimate(900, 990, now.Add(time.Minute))
+	assert.Equal(t, uint64(1020), e.CurrentRound)
+	assert.True(t, e.Expired)
+	assert.Contains(t, e.String(), "EXPIRED ~1m30s ago")
+
+	txs := []types.Transaction{
+		{Header: types.Header{FirstValid: 990, LastValid: 1100}},
+		{Header: types.Header{FirstValid: 995, LastValid: 1020}},
+	}
+
+	// the shortest window of the group is 60s while two more signatures are expected to take 2m
+	assert.True(t, warnValidity(c, txs, 2, time.Minute, now))
+	assert.False(t, warnValidity(c, txs, 1, 30*time.Second, now))
+}
@@ -1,9 +1,93 @@
 package ams
 
-import "github.com/algorand/go-algorand-sdk/client/v2/algod"
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/pkg/errors"
+)
+
+// consensus minimum balance requirements in microALGO
+const (
+	minBalanceBase         = 100000
+	minBalancePerAsset     = 100000
+	minBalancePerApp       = 100000
+	minBalancePerExtraPage = 100000
+	minBalancePerUint      = 28500
+	minBalancePerByteSlice = 50000
+	minBalancePerBox       = 2500
+	minBalancePerBoxByte   = 400
+)
+
+// DefaultWalletMaxPending is the default number of pending transactions shown on the dashboard
+const DefaultWalletMaxPending = 10
+
+// MinBalance computes the minimum balance of the account from its assets, apps and boxes
+func MinBalance(acc models.Account) uint64 {
+	apps := acc.TotalAppsOptedIn + acc.TotalCreatedApps
+
+	return minBalanceBase +
+		minBalancePerAsset*acc.TotalAssetsOptedIn +
+		minBalancePerApp*apps +
+		minBalancePerExtraPage*acc.AppsTotalExtraPages +
+		minBalancePerUint*acc.AppsTotalSchema.NumUint +
+		minBalancePerByteSlice*acc.AppsTotalSchema.NumByteSlice +
+		minBalancePerBox*acc.TotalBoxes +
+		minBalancePerBoxByte*acc.TotalBoxBytes
+}
+
+// WalletAsset is an asset holding of the wallet account
+type WalletAsset struct {
+	Info   AssetInfo
+	Amount uint64
+	Frozen bool
+}
+
+// WalletDashboard is the state of the wallet account
+type WalletDashboard struct {
+	Address    string
+	Balance    uint64
+	MinBalance uint64
+	AuthAddr   string
+
+	Assets      []WalletAsset
+	OptedInApps []uint64
+	CreatedApps []uint64
+
+	PendingTotal uint64
+	Pending      []types.SignedTxn
+}
+
+// Available is the balance that can be spent without going below the minimum balance
+func (d *WalletDashboard) Available() uint64 {
+	if d.Balance < d.MinBalance {
+		return 0
+	}
+
+	return d.Balance - d.MinBalance
+}
+
+// Wallet is an interactive dashboard and send tool for the address of the address source
 type Wallet struct {
-	ac *algod.Client
+	ac     *algod.Client
+	as     *AddressSource
+	s      Signer
+	r      *bufio.Reader
+	assets AssetResolver
+	book   *AddressBook
+
+	wait       uint64
+	maxPending uint64
 }
 
 type WalletOption func(*Wallet)
@@ -14,16 +98,391 @@ func WithWalletAlgod(ac *algod.Client) WalletOption {
 	}
 }
 
+// WithWalletAddressSource sets the account shown by the wallet, either a single or a multisig address
+func WithWalletAddressSource(as *AddressSource) WalletOption {
+	return func(w *Wallet) {
+		w.as = as
+	}
+}
+
+// WithWalletSigner sets the signer of the sent transactions
+func WithWalletSigner(s Signer) WalletOption {
+	return func(w *Wallet) {
+		w.s = s
+	}
+}
+
+// WithWalletInput sets the reader of the wallet commands, stdin by default
+func WithWalletInput(r io.Reader) WalletOption {
+	return func(w *Wallet) {
+		w.r = bufio.NewReader(r)
+	}
+}
+
+func WithWalletAssetResolver(r AssetResolver) WalletOption {
+	return func(w *Wallet) {
+		w.assets = r
+	}
+}
+
+func WithWalletAddressBook(b *AddressBook) WalletOption {
+	return func(w *Wallet) {
+		w.book = b
+	}
+}
+
+// WithWalletWaitRounds sets the number of rounds to wait for the sent transactions confirmation (0 - no waiting)
+func WithWalletWaitRounds(rounds uint64) WalletOption {
+	return func(w *Wallet) {
+		w.wait = rounds
+	}
+}
+
+// WithWalletMaxPending sets the number of pending transactions shown on the dashboard
+func WithWalletMaxPending(max uint64) WalletOption {
+	return func(w *Wallet) {
+		w.maxPending = max
+	}
+}
+
 func MakeWallet(opts ...WalletOption) (*Wallet, error) {
-	w := &Wallet{}
+	w := &Wallet{
+		r:          bufio.NewReader(os.Stdin),
+		wait:       DefaultWaitRounds,
+		maxPending: DefaultWalletMaxPending,
+	}
 
 	for _, opt := range opts {
 		opt(w)
 	}
 
+	if w.ac == nil {
+		return nil, errors.New("missing algod client")
+	}
+
+	if w.as == nil {
+		return nil, errors.New("missing address source")
+	}
+
+	if w.assets == nil {
+		r, err := MakeAlgodAssetResolver(w.ac)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to make asset resolver")
+		}
+
+		w.assets = r
+	}
+
 	return w, nil
 }
 
-func (w *Wallet) Run() error {
+// Dashboard reads the account state and its pending transactions from algod
+func (w *Wallet) Dashboard() (*WalletDashboard, error) {
+	ctx := context.Background()
+	addr := w.as.Address()
+
+	acc, err := w.ac.AccountInformation(addr).Do(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account information")
+	}
+
+	d := &WalletDashboard{
+		Address:    addr,
+		Balance:    acc.Amount,
+		MinBalance: MinBalance(acc),
+		AuthAddr:   acc.AuthAddr,
+	}
+
+	for _, h := range acc.Assets {
+		info, err := w.assets.ResolveAsset(h.AssetId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve asset: %d", h.AssetId)
+		}
+
+		d.Assets = append(d.Assets, WalletAsset{
+			Info:   info,
+			Amount: h.Amount,
+			Frozen: h.IsFrozen,
+		})
+	}
+
+	for _, ls := range acc.AppsLocalState {
+		d.OptedInApps = append(d.OptedInApps, ls.Id)
+	}
+
+	for _, app := range acc.CreatedApps {
+		d.CreatedApps = append(d.CreatedApps, app.Id)
+	}
+
+	if w.maxPending > 0 {
+		d.PendingTotal, d.Pending, err = w.ac.PendingTransactionsByAddress(addr).Max(w.maxPending).Do(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pending transactions")
+		}
+	}
+
+	return d, nil
+}
+
+func formatApps(ids []uint64) string {
+	res := make([]string, len(ids))
+	for i, id := range ids {
+		res[i] = strconv.FormatUint(id, 10)
+	}
+
+	return strings.Join(res, ", ")
+}
+
+func (w *Wallet) printDashboard(d *WalletDashboard) {
+	fmt.Println("Address:", w.book.Format(d.Address))
+
+	if ma := w.as.Multisig(); ma != nil {
+		fmt.Printf("Multisig: %d of %d\n", ma.Threshold, len(ma.Pks))
+		for i, pk := range ma.Pks {
+			var addr types.Address
+			copy(addr[:], pk)
+			fmt.Printf("Member #%d: %s\n", i, w.book.Format(addr.String()))
+		}
+	}
+
+	if len(d.AuthAddr) > 0 {
+		fmt.Println("[!] Rekeyed - auth address:", w.book.Format(d.AuthAddr))
+	} else {
+		fmt.Println("Auth address: self")
+	}
+
+	fmt.Println("Balance:", FormatAlgos(d.Balance))
+	fmt.Println("Minimum balance:", FormatAlgos(d.MinBalance))
+	fmt.Println("Available:", FormatAlgos(d.Available()))
+
+	if len(d.Assets) > 0 {
+		fmt.Println("Assets:")
+		for _, a := range d.Assets {
+			line := FormatAssetAmount(a.Amount, a.Info)
+			if a.Frozen {
+				line += " [frozen]"
+			}
+			fmt.Println(" ", line)
+		}
+	}
+
+	if len(d.OptedInApps) > 0 {
+		fmt.Println("Opted-in apps:", formatApps(d.OptedInApps))
+	}
+
+	if len(d.CreatedApps) > 0 {
+		fmt.Println("Created apps:", formatApps(d.CreatedApps))
+	}
+
+	if d.PendingTotal > 0 {
+		fmt.Printf("Pending transactions: %d\n", d.PendingTotal)
+		for _, stx := range d.Pending {
+			fmt.Printf("  %s %s\n", stx.Txn.Type, crypto.GetTxID(stx.Txn))
+		}
+	}
+}
+
+func printWalletHelp() {
+	fmt.Println("Commands:")
+	fmt.Println("  info                                 show the account dashboard")
+	fmt.Println("  pay <receiver> <amount> [note]       send ALGO")
+	fmt.Println("  axfer <asset-id> <receiver> <amount> [note]")
+	fmt.Println("                                       send an asset")
+	fmt.Println("  help                                 show the commands")
+	fmt.Println("  quit                                 exit the wallet")
+}
+
+// noteArg joins the remaining command args into a transaction note
+func noteArg(args []string) []byte {
+	if len(args) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(args, " "))
+}
+
+// Payment builds a payment of the amount in ALGO from the wallet account
+func (w *Wallet) Payment(receiver string, amount string, note []byte) (types.Transaction, error) {
+	microAlgos, err := ParseDecimal(amount, 6)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	sp, err := w.ac.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return types.Transaction{}, errors.Wrap(err, "failed to get suggested params")
+	}
+
+	txn, err := future.MakePaymentTxn(w.as.Address(), receiver, microAlgos, note, "", sp)
+	if err != nil {
+		return types.Transaction{}, errors.Wrap(err, "failed to make payment transaction")
+	}
+
+	return txn, nil
+}
+
+// AssetTransfer builds a transfer of the amount in asset units from the wallet account
+func (w *Wallet) AssetTransfer(assetID uint64, receiver string, amount string, note []byte) (types.Transaction, error) {
+	info, err := w.assets.ResolveAsset(assetID)
+	if err != nil {
+		return types.Transaction{}, errors.Wrapf(err, "failed to resolve asset: %d", assetID)
+	}
+
+	if info.Unknown {
+		return types.Transaction{}, errors.Errorf("unknown asset: %d", assetID)
+	}
+
+	units, err := ParseDecimal(amount, info.Decimals)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+
+	sp, err := w.ac.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return types.Transaction{}, errors.Wrap(err, "failed to get suggested params")
+	}
+
+	txn, err := future.MakeAssetTransferTxn(w.as.Address(), receiver, units, note, sp, "", assetID)
+	if err != nil {
+		return types.Transaction{}, errors.Wrap(err, "failed to make asset transfer transaction")
+	}
+
+	return txn, nil
+}
+
+// confirm shows the transaction with its risk findings and asks the user to send it
+func (w *Wallet) confirm(txn types.Transaction) (bool, error) {
+	ra, err := MakeRiskAnalyzer(
+		WithRiskOwnAddresses([]string{w.as.Address()}),
+		WithRiskAddressBook(w.book),
+	)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to make risk analyzer")
+	}
+
+	g := DecodeTxnGroup([]types.SignedTxn{{Txn: txn}},
+		WithFormatAssetResolver(w.assets),
+		WithFormatAddressBook(w.book),
+		WithFormatRiskAnalyzer(ra),
+	)
+
+	fmt.Print(g.Text())
+
+	return ConfirmFindings(w.r, g.Findings(), "send the transaction")
+}
+
+// send confirms the transaction and runs a SendRunner for it
+func (w *Wallet) send(txn types.Transaction) error {
+	if w.s == nil {
+		return errors.New("no signer configured")
+	}
+
+	ok, err := w.confirm(txn)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		fmt.Println("Cancelled")
+		return nil
+	}
+
+	var r Runner
+	r, err = MakeSendRunner([]types.SignedTxn{{Txn: txn}},
+		WithSendRunnerSigner(w.s),
+		WithSendRunnerAlgod(w.ac),
+		WithSendRunnerWaitRounds(w.wait),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to make send runner")
+	}
+
+	return r.Run()
+}
+
+func (w *Wallet) info() error {
+	d, err := w.Dashboard()
+	if err != nil {
+		return err
+	}
+
+	w.printDashboard(d)
+
 	return nil
 }
+
+func (w *Wallet) exec(cmd string, args []string) error {
+	switch cmd {
+	case "info", "refresh":
+		return w.info()
+	case "pay":
+		if len(args) < 2 {
+			return errors.New("usage: pay <receiver> <amount> [note]")
+		}
+
+		txn, err := w.Payment(args[0], args[1], noteArg(args[2:]))
+		if err != nil {
+			return err
+		}
+
+		return w.send(txn)
+	case "axfer":
+		if len(args) < 3 {
+			return errors.New("usage: axfer <asset-id> <receiver> <amount> [note]")
+		}
+
+		id, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "invalid asset id: %s", args[0])
+		}
+
+		txn, err := w.AssetTransfer(id, args[1], args[2], noteArg(args[3:]))
+		if err != nil {
+			return err
+		}
+
+		return w.send(txn)
+	case "help":
+		printWalletHelp()
+		return nil
+	default:
+		return errors.Errorf("unknown command: %s", cmd)
+	}
+}
+
+// Run shows the dashboard and executes the wallet commands until quit or the end of the input
+func (w *Wallet) Run() error {
+	err := w.info()
+	if err != nil {
+		return err
+	}
+
+	printWalletHelp()
+
+	for {
+		fmt.Print("> ")
+
+		line, err := w.r.ReadString('\n')
+		if err == io.EOF && len(line) == 0 {
+			return nil
+		}
+
+		if err != nil && err != io.EOF {
+			return errors.Wrap(err, "failed to read command")
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "quit" || fields[0] == "exit" {
+			return nil
+		}
+
+		err = w.exec(fields[0], fields[1:])
+		if err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+}
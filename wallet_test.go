@@ -0,0 +1,132 @@
+package ams
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMinBalance(t *testing.T) {
+	assert.Equal(t, uint64(100000), MinBalance(models.Account{}))
+
+	acc := models.Account{
+		TotalAssetsOptedIn:  2,
+		TotalAppsOptedIn:    1,
+		TotalCreatedApps:    1,
+		AppsTotalExtraPages: 1,
+		AppsTotalSchema:     models.ApplicationStateSchema{NumUint: 2, NumByteSlice: 1},
+		TotalBoxes:          1,
+		TotalBoxBytes:       10,
+	}
+
+	assert.Equal(t, uint64(100000+200000+200000+100000+57000+50000+2500+4000), MinBalance(acc))
+}
+
+// walletTestHandlers serves an account holding 5 Algo, an opted in app and a frozen USDC holding
+func walletTestHandlers(addr string) map[string]http.HandlerFunc {
+	return map[string]http.HandlerFunc{
+		"/v2/accounts/" + addr: jsonHandler(map[string]interface{}{
+			"address":               addr,
+			"amount":                5000000,
+			"total-assets-opted-in": 1,
+			"total-apps-opted-in":   1,
+			"assets":                []interface{}{map[string]interface{}{"asset-id": 31566704, "amount": 1250500000, "is-frozen": true}},
+			"apps-local-state":      []interface{}{map[string]interface{}{"id": 123}},
+		}),
+		"/v2/accounts/" + addr + "/transactions/pending": msgpackHandler(map[string]interface{}{"top-transactions": []interface{}{}, "total-transactions": 0}),
+		"/v2/assets/31566704": jsonHandler(map[string]interface{}{
+			"index":  31566704,
+			"params": map[string]interface{}{"creator": addr, "decimals": 6, "total": 1000, "unit-name": "USDC"},
+		}),
+	}
+}
+
+func TestWalletDashboard(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	addr := acc.Address.String()
+
+	ac, _ := makeTestAlgod(t, walletTestHandlers(addr))
+
+	as, err := MakeAddressSource(WithAddressString(addr))
+	assert.NoError(t, err)
+
+	w, err := MakeWallet(WithWalletAlgod(ac), WithWalletAddressSource(as))
+	assert.NoError(t, err)
+
+	d, err := w.Dashboard()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5000000), d.Balance)
+	assert.Equal(t, uint64(300000), d.MinBalance)
+	assert.Equal(t, uint64(4700000), d.Available())
+	assert.Equal(t, []uint64{123}, d.OptedInApps)
+	assert.Len(t, d.Assets, 1)
+	assert.Equal(t, "USDC", d.Assets[0].Info.UnitName)
+	assert.True(t, d.Assets[0].Frozen)
+}
+
+func TestWalletSend(t *testing.T) {
+	acc := crypto.GenerateAccount()
+	addr := acc.Address.String()
+	receiver := crypto.GenerateAccount().Address.String()
+
+	var submitted []types.SignedTxn
+	handlers := walletTestHandlers(addr)
+	handlers["/v2/transactions/params"] = jsonHandler(map[string]interface{}{
+		"consensus-version": "future",
+		"fee":               0,
+		"genesis-hash":      "SGO1GKSzyE7IEPItTxCByw9x8FmnrCDexi9/cOUJOiI=",
+		"genesis-id":        "testnet-v1.0",
+		"last-round":        100,
+		"min-fee":           1000,
+	})
+	handlers["/v2/transactions"] = func(w http.ResponseWriter, r *http.Request) {
+		dec := msgpack.NewDecoder(r.Body)
+		for {
+			var stx types.SignedTxn
+			if dec.Decode(&stx) != nil {
+				break
+			}
+			submitted = append(submitted, stx)
+		}
+		w.Write([]byte(`{"txId":"X"}`))
+	}
+
+	ac, _ := makeTestAlgod(t, handlers)
+
+	as, err := MakeAddressSource(WithAddressString(addr))
+	assert.NoError(t, err)
+
+	s, err := MakeLocalSigner(addr, acc.PrivateKey)
+	assert.NoError(t, err)
+
+	input := "pay " + receiver + " 1.5 hello\ny\naxfer 31566704 " + receiver + " 2.25\nn\nquit\n"
+
+	w, err := MakeWallet(
+		WithWalletAlgod(ac),
+		WithWalletAddressSource(as),
+		WithWalletSigner(s),
+		WithWalletWaitRounds(0),
+		WithWalletInput(strings.NewReader(input)),
+	)
+	assert.NoError(t, err)
+
+	err = w.Run()
+	assert.NoError(t, err)
+
+	assert.Len(t, submitted, 1)
+	assert.Equal(t, types.PaymentTx, submitted[0].Txn.Type)
+	assert.Equal(t, types.MicroAlgos(1500000), submitted[0].Txn.Amount)
+	assert.Equal(t, receiver, submitted[0].Txn.Receiver.String())
+	assert.Equal(t, []byte("hello"), submitted[0].Txn.Note)
+	assert.NotEqual(t, types.Signature{}, submitted[0].Sig)
+
+	txn, err := w.AssetTransfer(31566704, receiver, "2.25", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2250000), txn.AssetAmount)
+}